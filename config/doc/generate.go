@@ -66,7 +66,11 @@ func (gen generator) descendNode(node DocNode, typ reflect.Type, path []string)
 		if err != nil {
 			return err
 		}
-		if _, err := fmt.Fprintf(gen.w, "%s* **%s%s%s** (%s): %s\n", strings.Repeat("  ", len(path)), optional, child.Name, optional, typeName(field.Type), desc); err != nil {
+		typ, err := child.typeName(gen.vers, field.Type)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(gen.w, "%s* **%s%s%s** (%s): %s\n", strings.Repeat("  ", len(path)), optional, child.Name, optional, typ, desc); err != nil {
 			return err
 		}
 		// recurse
@@ -45,6 +45,10 @@ type DocNode struct {
 	RequiredIf  Constraints `yaml:"required-if"`
 	Transforms  []Transform `yaml:"transforms"`
 	Children    []DocNode   `yaml:"children"`
+	// overrides the type derived from the struct field, if TypeIf is
+	// empty or matches
+	Type   string      `yaml:"type"`
+	TypeIf Constraints `yaml:"type-if"`
 
 	Component string `yaml:"use"`
 	After     string `yaml:"after"`
@@ -214,6 +218,19 @@ func (node *DocNode) required(vers VariantVersions) (*bool, error) {
 	return node.Required, nil
 }
 
+func (node *DocNode) typeName(vers VariantVersions, typ reflect.Type) (string, error) {
+	if node.Type != "" {
+		matches, err := node.TypeIf.matches(vers)
+		if err != nil {
+			return "", fmt.Errorf("field %q: %w", node.Name, err)
+		}
+		if !util.IsFalse(matches) {
+			return node.Type, nil
+		}
+	}
+	return typeName(typ), nil
+}
+
 func (node *DocNode) transforms() []Transform {
 	var ret []Transform
 	var descend func(node *DocNode, inheritedOnly bool)
@@ -249,6 +266,10 @@ func (node *DocNode) merge(override DocNode) error {
 		node.RequiredIf = append(node.RequiredIf, override.RequiredIf...)
 	}
 	node.Transforms = append(node.Transforms, override.Transforms...)
+	if override.Type != "" {
+		node.Type = override.Type
+		node.TypeIf = override.TypeIf
+	}
 	if override.Component != "" {
 		node.Component = override.Component
 	}
@@ -46,9 +46,23 @@ var (
 	ErrPartitionsMisaligned      = errors.New("partitions misaligned")
 	ErrOverwriteAndNilSource     = errors.New("overwrite must be false if source is unspecified")
 	ErrVerificationAndNilSource  = errors.New("source must be specified if verification is specified")
+	ErrDeviceAndNilSource        = errors.New("source must be specified if device is true")
+	ErrDeviceAndOverwrite        = errors.New("overwrite must be false if device is true")
+	ErrDeviceAndAppend           = errors.New("cannot append to a device")
+	ErrDevicePathNotInDev        = errors.New("path must be under /dev/ if device is true")
+	ErrDeviceAndMode             = errors.New("mode cannot be specified if device is true")
+	ErrDeviceAndOwner            = errors.New("user and group cannot be specified if device is true")
+	ErrDeviceAndOnConflict       = errors.New("onConflict cannot be specified if device is true")
+	ErrInvalidOnConflict         = errors.New("onConflict must be \"fail\", \"replace\", or \"keep\"")
+	ErrOverwriteAndOnConflict    = errors.New("onConflict cannot be \"fail\" or \"keep\" if overwrite is true")
 	ErrFilesystemInvalidFormat   = errors.New("invalid filesystem format")
 	ErrLabelNeedsFormat          = errors.New("filesystem must specify format if label is specified")
 	ErrFormatNilWithOthers       = errors.New("format cannot be empty when path, label, uuid, wipeFilesystem, options, or mountOptions is specified")
+	ErrFsckPassInvalid           = errors.New("fsck pass number must be 0, 1, or 2")
+	ErrFsckPassNeedsFormat       = errors.New("fsck pass number cannot be nonzero unless format is a checkable filesystem")
+	ErrEspMirrorNeedsVfat        = errors.New("ESP mirrors must have format vfat")
+	ErrEspMirrorSourceRequired   = errors.New("ESP mirror source is required")
+	ErrEspMirrorSourceIsDevice   = errors.New("ESP mirror source cannot be the filesystem device itself")
 	ErrExt4LabelTooLong          = errors.New("filesystem labels cannot be longer than 16 characters when using ext4")
 	ErrBtrfsLabelTooLong         = errors.New("filesystem labels cannot be longer than 256 characters when using btrfs")
 	ErrXfsLabelTooLong           = errors.New("filesystem labels cannot be longer than 12 characters when using xfs")
@@ -64,7 +78,10 @@ var (
 	ErrTangThumbprintRequired    = errors.New("thumbprint is required")
 	ErrInvalidTangAdvertisement  = errors.New("advertisement is not valid JSON")
 	ErrFileIllegalMode           = errors.New("illegal file mode")
+	ErrModeStringInvalid         = errors.New("mode strings must be octal (e.g. \"0644\") or symbolic (e.g. \"u=rw,go=r\")")
 	ErrModeSpecialBits           = errors.New("setuid/setgid/sticky bits are not supported in spec versions older than 3.4.0")
+	ErrModeWorldWritable         = errors.New("mode is writable by everyone; set acknowledgeInsecureMode to silence this warning")
+	ErrModeSetuidFetched         = errors.New("setuid/setgid mode on file with remote contents; set acknowledgeInsecureMode to silence this warning")
 	ErrBothIDAndNameSet          = errors.New("cannot set both id and name")
 	ErrLabelTooLong              = errors.New("partition labels may not exceed 36 characters")
 	ErrDoesntMatchGUIDRegex      = errors.New("doesn't match the form \"01234567-89AB-CDEF-EDCB-A98765432101\"")
@@ -72,6 +89,9 @@ var (
 	ErrNoPath                    = errors.New("path not specified")
 	ErrPathRelative              = errors.New("path not absolute")
 	ErrDirtyPath                 = errors.New("path is not fully simplified")
+	ErrPathTooLong               = errors.New("path may not exceed 4095 bytes")
+	ErrPathComponentTooLong      = errors.New("path components may not exceed 255 bytes")
+	ErrPathContainsNUL           = errors.New("path may not contain NUL characters")
 	ErrPartitionsOverwritten     = errors.New("filesystem overwrites partitioned device")
 	ErrFilesystemImplicitWipe    = errors.New("device matches disk with wipeTable enabled; filesystem will be wiped")
 	ErrRaidLevelRequired         = errors.New("raid level is required")
@@ -85,6 +105,23 @@ var (
 	ErrInvalidProxy              = errors.New("proxies must be http(s)")
 	ErrInsecureProxy             = errors.New("insecure plaintext HTTP proxy specified for HTTPS resources")
 	ErrPathConflictsSystemd      = errors.New("path conflicts with systemd unit or dropin")
+	ErrUnknownRaidDevice         = errors.New("device refers to a RAID array that is not declared in the config")
+	ErrRaidDeviceByNumber        = errors.New("device refers to a RAID array by kernel number, which is not stable; use /dev/md/<name> instead")
+	ErrUnknownLuksDevice         = errors.New("device refers to a LUKS volume that is not declared in the config")
+	ErrUnknownPartition          = errors.New("device refers to a partition that is deleted or not declared on a disk whose partition table is wiped")
+
+	// EFI section errors
+	ErrEfiLabelRequired  = errors.New("boot entry label is required")
+	ErrEfiLoaderRequired = errors.New("boot entry loader is required")
+	ErrEfiLoaderRelative = errors.New("boot entry loader path not absolute")
+
+	// Merged config restriction errors
+	ErrRestrictedPath       = errors.New("path is outside the paths the parent config allows")
+	ErrRestrictedLinkTarget = errors.New("link target is outside the paths the parent config allows")
+	ErrRestrictedDisks      = errors.New("storage devices are not allowed by the parent config")
+	ErrRestrictedPasswd     = errors.New("users and groups are not allowed by the parent config")
+	ErrRestrictedSystemd    = errors.New("systemd units are not allowed by the parent config")
+	ErrRestrictedBoot       = errors.New("kernel arguments and boot entries are not allowed by the parent config")
 
 	// Systemd section errors
 	ErrInvalidSystemdExt       = errors.New("invalid systemd unit extension")
@@ -96,6 +133,8 @@ var (
 	ErrSourceRequired                  = errors.New("source is required")
 	ErrInvalidScheme                   = errors.New("invalid url scheme")
 	ErrInvalidUrl                      = errors.New("unable to parse url")
+	ErrInvalidUrlEscape                = errors.New("invalid percent-encoding in url")
+	ErrUnescapedSpaceInUrl             = errors.New("url contains a space; spaces must be percent-encoded as %20")
 	ErrInvalidHTTPHeader               = errors.New("unable to parse HTTP header")
 	ErrEmptyHTTPHeaderName             = errors.New("HTTP header name can't be empty")
 	ErrUnsupportedSchemeForHTTPHeaders = errors.New("cannot use HTTP headers with this source scheme")
@@ -0,0 +1,62 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// HashFunction is a hash function that can be used in verification hashes.
+type HashFunction struct {
+	// New returns a hash.Hash computing the function.
+	New func() hash.Hash
+	// Size is the length of a sum in bytes.
+	Size int
+}
+
+var hashFunctions = map[string]HashFunction{
+	"sha256": {New: sha256.New, Size: sha256.Size},
+	"sha512": {New: sha512.New, Size: sha512.Size},
+}
+
+// RegisterHashFunction makes a hash function available in verification
+// hashes of the form "<name>-<sum>", in addition to sha256 and sha512. It's
+// meant to be called from init functions by distributions adding
+// algorithms, and panics if name is already registered or contains a "-".
+// Only the latest (experimental) config spec accepts registered functions.
+func RegisterHashFunction(name string, f HashFunction) {
+	if strings.Contains(name, "-") {
+		panic(fmt.Sprintf("hash function name %q contains a \"-\"", name))
+	}
+	if _, ok := hashFunctions[name]; ok {
+		panic(fmt.Sprintf("hash function %q already registered", name))
+	}
+	hashFunctions[name] = f
+}
+
+// unregisterHashFunction undoes RegisterHashFunction, for tests.
+func unregisterHashFunction(name string) {
+	delete(hashFunctions, name)
+}
+
+// GetHashFunction returns the hash function registered under name.
+func GetHashFunction(name string) (HashFunction, bool) {
+	f, ok := hashFunctions[name]
+	return f, ok
+}
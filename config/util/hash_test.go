@@ -0,0 +1,60 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
+	iutil "github.com/coreos/ignition/v2/internal/util"
+)
+
+func TestRegisteredHashFunction(t *testing.T) {
+	util.RegisterHashFunction("crc32", util.HashFunction{
+		New:  func() hash.Hash { return crc32.NewIEEE() },
+		Size: crc32.Size,
+	})
+	t.Cleanup(func() { util.UnregisterHashFunction("crc32") })
+
+	verify := types.Verification{Hash: util.StrToPtr("crc32-3610a686")}
+	if err := iutil.AssertValid(verify, []byte("hello")); err != nil {
+		t.Errorf("bad err: %v", err)
+	}
+	hasher, err := iutil.GetHasher(verify)
+	if err != nil {
+		t.Fatalf("bad err: %v", err)
+	}
+	hasher.Write([]byte("hello"))
+	if sum := hex.EncodeToString(hasher.Sum(nil)); sum != "3610a686" {
+		t.Errorf("bad sum: %s", sum)
+	}
+}
+
+func TestRegisterHashFunctionConflict(t *testing.T) {
+	for _, name := range []string{"sha512", "crc-32"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("registering %q didn't panic", name)
+				}
+			}()
+			util.RegisterHashFunction(name, util.HashFunction{})
+		}()
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+
+	vjson "github.com/coreos/vcontext/json"
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+var (
+	octalModeRegex    = regexp.MustCompile(`^[0-7]{3,4}$`)
+	symbolicModeRegex = regexp.MustCompile(`^([ugoa]*)((?:[-+=][rwxst]*)+)$`)
+	symbolicOpRegex   = regexp.MustCompile(`[-+=][rwxst]*`)
+)
+
+// ParseMode parses a file mode given as a string, either in octal (e.g.
+// "0644") or as a comma-separated list of symbolic clauses as accepted by
+// chmod (e.g. "u=rw,go=r"). Symbolic clauses are applied to an initial mode
+// of 0, and a clause without any of u, g, o, or a applies to everyone.
+func ParseMode(s string) (int, error) {
+	if octalModeRegex.MatchString(s) {
+		mode, err := strconv.ParseInt(s, 8, 0)
+		if err != nil {
+			return 0, errors.ErrModeStringInvalid
+		}
+		return int(mode), nil
+	}
+
+	mode := 0
+	for _, clause := range strings.Split(s, ",") {
+		matches := symbolicModeRegex.FindStringSubmatch(clause)
+		if matches == nil {
+			return 0, errors.ErrModeStringInvalid
+		}
+		who := matches[1]
+		if who == "" || strings.Contains(who, "a") {
+			who = "ugo"
+		}
+		for _, op := range symbolicOpRegex.FindAllString(matches[2], -1) {
+			bits := 0
+			for _, perm := range op[1:] {
+				bits |= symbolicBits(who, perm)
+			}
+			switch op[0] {
+			case '+':
+				mode |= bits
+			case '-':
+				mode &^= bits
+			case '=':
+				mode = mode&^symbolicBits(who, 0) | bits
+			}
+		}
+	}
+	return mode, nil
+}
+
+// symbolicBits returns the mode bits that perm sets for the classes in who,
+// or all the bits belonging to those classes if perm is 0.
+func symbolicBits(who string, perm rune) int {
+	bits := 0
+	for _, class := range who {
+		shift := map[rune]int{'u': 6, 'g': 3, 'o': 0}[class]
+		switch perm {
+		case 'r':
+			bits |= 04 << shift
+		case 'w':
+			bits |= 02 << shift
+		case 'x':
+			bits |= 01 << shift
+		case 's':
+			bits |= map[rune]int{'u': 04000, 'g': 02000}[class]
+		case 't':
+			bits |= map[rune]int{'o': 01000}[class]
+		case 0:
+			bits |= 07<<shift | map[rune]int{'u': 04000, 'g': 02000, 'o': 01000}[class]
+		}
+	}
+	return bits
+}
+
+// NormalizeModes rewrites any file or directory modes in rawConfig that are
+// given as strings into the equivalent integers, so that the config can be
+// unmarshalled into the types; the schema, and so the types generated from
+// it, only describe integer modes. Only the modes are rewritten, and each is
+// padded with whitespace to its original length so that offsets into the
+// config are unchanged. Configs which aren't valid JSON are returned
+// unchanged for the unmarshalling to report. If a mode can't be parsed, the
+// returned report describes it.
+func NormalizeModes(rawConfig []byte) ([]byte, report.Report, error) {
+	var config map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(rawConfig))
+	decoder.UseNumber()
+	if err := decoder.Decode(&config); err != nil {
+		return rawConfig, report.Report{}, nil
+	}
+
+	r := report.Report{}
+	var modes []path.ContextPath
+	var values []int
+	storage, _ := config["storage"].(map[string]interface{})
+	for _, section := range []string{"files", "directories"} {
+		nodes, _ := storage[section].([]interface{})
+		for i, n := range nodes {
+			node, _ := n.(map[string]interface{})
+			s, ok := node["mode"].(string)
+			if !ok {
+				continue
+			}
+			c := path.New("json", "storage", section, i, "mode")
+			mode, err := ParseMode(s)
+			if err != nil {
+				r.AddOnError(c, err)
+				continue
+			}
+			modes = append(modes, c)
+			values = append(values, mode)
+		}
+	}
+	if len(modes) == 0 && !r.IsFatal() {
+		return rawConfig, r, nil
+	}
+	cxt, err := vjson.UnmarshalToContext(rawConfig)
+	if err != nil {
+		return nil, r, err
+	}
+	if r.IsFatal() {
+		r.Correlate(cxt)
+		return nil, r, errors.ErrInvalid
+	}
+
+	normalized := make([]byte, len(rawConfig))
+	copy(normalized, rawConfig)
+	for i, c := range modes {
+		node, err := cxt.Get(c)
+		if err != nil {
+			return nil, r, err
+		}
+		m := node.GetMarker()
+		if m.StartP == nil || m.EndP == nil {
+			return nil, r, fmt.Errorf("couldn't locate %s", c)
+		}
+		// the end index is inclusive; a parseable mode string is never
+		// shorter than the integer
+		value := normalized[m.StartP.Index : m.EndP.Index+1]
+		n := copy(value, strconv.Itoa(values[i]))
+		for j := n; j < len(value); j++ {
+			value[j] = ' '
+		}
+	}
+	return normalized, r, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeModes(t *testing.T) {
+	tests := []struct {
+		in   string
+		out  string
+		path path.ContextPath
+		err  error
+	}{
+		{
+			in:  `{"storage": {"files": [{"path": "/a"}]}}`,
+			out: `{"storage": {"files": [{"path": "/a"}]}}`,
+		},
+		{
+			in:  `{"storage": {"files": [{"path": "/a", "mode": 420}]}}`,
+			out: `{"storage": {"files": [{"path": "/a", "mode": 420}]}}`,
+		},
+		// only the mode is rewritten
+		{
+			in:  `{"storage": {"files": [{"path": "/a<b>&c", "mode": "0644"}]}}`,
+			out: `{"storage": {"files": [{"path": "/a<b>&c", "mode": 420   }]}}`,
+		},
+		{
+			in:  `{"storage": {"directories": [{"path": "/a"}, {"path": "/b", "mode": "u=rwx,go=rx"}], "files": [{"mode":"=t"}]}}`,
+			out: `{"storage": {"directories": [{"path": "/a"}, {"path": "/b", "mode": 493          }], "files": [{"mode":512 }]}}`,
+		},
+		// invalid JSON is left for unmarshalling to report
+		{
+			in:  `{"storage": {"files": [{"mode": "0644"}`,
+			out: `{"storage": {"files": [{"mode": "0644"}`,
+		},
+		{
+			in:   `{"storage": {"files": [{"path": "/a"}, {"path": "/b", "mode": "u=rw,q=r"}]}}`,
+			path: path.New("json", "storage", "files", 1, "mode"),
+			err:  errors.ErrInvalid,
+		},
+	}
+
+	for i, test := range tests {
+		out, r, err := NormalizeModes([]byte(test.in))
+		assert.Equal(t, test.err, err, "#%d: bad error", i)
+		if test.err != nil {
+			if assert.Len(t, r.Entries, 1, "#%d: bad report", i) {
+				assert.Equal(t, test.path, r.Entries[0].Context, "#%d: bad report path", i)
+				assert.Equal(t, errors.ErrModeStringInvalid.Error(), r.Entries[0].Message, "#%d: bad report message", i)
+			}
+			continue
+		}
+		assert.Equal(t, test.out, string(out), "#%d: bad output", i)
+	}
+}
@@ -39,8 +39,14 @@ func Parse(rawConfig []byte) (types.Config, report.Report, error) {
 		return types.Config{}, report.Report{}, errors.ErrEmpty
 	}
 
+	// accept string modes, which the schema doesn't describe
+	normalized, modeRpt, err := util.NormalizeModes(rawConfig)
+	if err != nil {
+		return types.Config{}, modeRpt, err
+	}
+
 	var config types.Config
-	if rpt, err := util.HandleParseErrors(rawConfig, &config); err != nil {
+	if rpt, err := util.HandleParseErrors(normalized, &config); err != nil {
 		return types.Config{}, rpt, err
 	}
 
@@ -51,6 +57,7 @@ func Parse(rawConfig []byte) (types.Config, report.Report, error) {
 	}
 
 	rpt := validate.ValidateWithContext(config, rawConfig)
+	rpt.Merge(modeRpt)
 	if rpt.IsFatal() {
 		return types.Config{}, rpt, errors.ErrInvalid
 	}
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -151,6 +152,63 @@ func TestParse(t *testing.T) {
 			in:  in{config: []byte(`{"ignition": {"version": "3.5.0-experimental"}, "storage": {"filesystems": [{"format": "ext4", "label": "zzzzzzzzzzzzzzzzzzzzzzzzzzz"}]}}`)},
 			out: out{err: errors.ErrInvalid},
 		},
+		{
+			in: in{config: []byte(`{"ignition": {"version": "3.5.0-experimental"}, "storage": {"files": [{"path": "/a", "mode": 420}]}}`)},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+				Storage:  types.Storage{Files: []types.File{{Node: types.Node{Path: "/a"}, FileEmbedded1: types.FileEmbedded1{Mode: util.IntToPtr(420)}}}},
+			}},
+		},
+		{
+			in: in{config: []byte(`{"ignition": {"version": "3.5.0-experimental"}, "storage": {"files": [{"path": "/a", "mode": "0644"}]}}`)},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+				Storage:  types.Storage{Files: []types.File{{Node: types.Node{Path: "/a"}, FileEmbedded1: types.FileEmbedded1{Mode: util.IntToPtr(0644)}}}},
+			}},
+		},
+		{
+			in: in{config: []byte(`{"ignition": {"version": "3.5.0-experimental"}, "storage": {"directories": [{"path": "/a", "mode": "755"}]}}`)},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+				Storage:  types.Storage{Directories: []types.Directory{{Node: types.Node{Path: "/a"}, DirectoryEmbedded1: types.DirectoryEmbedded1{Mode: util.IntToPtr(0755)}}}},
+			}},
+		},
+		{
+			in: in{config: []byte(`{"ignition": {"version": "3.5.0-experimental"}, "storage": {"files": [{"path": "/a", "mode": "u=rw,go=r"}]}}`)},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+				Storage:  types.Storage{Files: []types.File{{Node: types.Node{Path: "/a"}, FileEmbedded1: types.FileEmbedded1{Mode: util.IntToPtr(0644)}}}},
+			}},
+		},
+		{
+			in: in{config: []byte(`{"ignition": {"version": "3.5.0-experimental"}, "storage": {"directories": [{"path": "/a", "mode": "a=rx,u+w"}]}}`)},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+				Storage:  types.Storage{Directories: []types.Directory{{Node: types.Node{Path: "/a"}, DirectoryEmbedded1: types.DirectoryEmbedded1{Mode: util.IntToPtr(0755)}}}},
+			}},
+		},
+		{
+			in: in{config: []byte(`{"ignition": {"version": "3.5.0-experimental"}, "storage": {"directories": [{"path": "/a", "mode": "u=rwxs,g=rx,o=,+t"}]}}`)},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+				Storage:  types.Storage{Directories: []types.Directory{{Node: types.Node{Path: "/a"}, DirectoryEmbedded1: types.DirectoryEmbedded1{Mode: util.IntToPtr(05750)}}}},
+			}},
+		},
+		{
+			in: in{config: []byte(`{"ignition": {"version": "3.5.0-experimental"}, "storage": {"files": [{"path": "/a", "mode": "ug=rw,g-w"}]}}`)},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+				Storage:  types.Storage{Files: []types.File{{Node: types.Node{Path: "/a"}, FileEmbedded1: types.FileEmbedded1{Mode: util.IntToPtr(0640)}}}},
+			}},
+		},
+		{
+			in:  in{config: []byte(`{"ignition": {"version": "3.5.0-experimental"}, "storage": {"files": [{"path": "/a", "mode": "0999"}]}}`)},
+			out: out{err: errors.ErrInvalid},
+		},
+		{
+			in:  in{config: []byte(`{"ignition": {"version": "3.5.0-experimental"}, "storage": {"files": [{"path": "/a", "mode": "u=rw,q=r"}]}}`)},
+			out: out{err: errors.ErrInvalid},
+		},
 	}
 
 	testsCompt := []struct {
@@ -22,14 +22,103 @@ import (
 
 func translateIgnition(old old_types.Ignition) (ret types.Ignition) {
 	// use a new translator so we don't recurse infinitely
-	translate.NewTranslator().Translate(&old, &ret)
+	tr := translate.NewTranslator()
+	tr.AddCustomTranslator(translateIgnitionConfig)
+	tr.AddCustomTranslator(translateTimeouts)
+	tr.Translate(&old, &ret)
 	ret.Version = types.MaxVersion.String()
 	return
 }
 
+func translateIgnitionConfig(old old_types.IgnitionConfig) (ret types.IgnitionConfig) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.Merge, &ret.Merge)
+	tr.Translate(&old.Replace, &ret.Replace)
+	return
+}
+
+func translateTimeouts(old old_types.Timeouts) (ret types.Timeouts) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.HTTPResponseHeaders, &ret.HTTPResponseHeaders)
+	tr.Translate(&old.HTTPTotal, &ret.HTTPTotal)
+	return
+}
+
+func translateFilesystem(old old_types.Filesystem) (ret types.Filesystem) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.Device, &ret.Device)
+	tr.Translate(&old.Format, &ret.Format)
+	tr.Translate(&old.Label, &ret.Label)
+	tr.Translate(&old.MountOptions, &ret.MountOptions)
+	tr.Translate(&old.Options, &ret.Options)
+	tr.Translate(&old.Path, &ret.Path)
+	tr.Translate(&old.UUID, &ret.UUID)
+	tr.Translate(&old.WipeFilesystem, &ret.WipeFilesystem)
+	return
+}
+
+func translateDirectoryEmbedded1(old old_types.DirectoryEmbedded1) (ret types.DirectoryEmbedded1) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.Mode, &ret.Mode)
+	return
+}
+
+func translateFileEmbedded1(old old_types.FileEmbedded1) (ret types.FileEmbedded1) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.Append, &ret.Append)
+	tr.Translate(&old.Contents, &ret.Contents)
+	tr.Translate(&old.Mode, &ret.Mode)
+	return
+}
+
+func translateNode(old old_types.Node) (ret types.Node) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.Group, &ret.Group)
+	tr.Translate(&old.Overwrite, &ret.Overwrite)
+	tr.Translate(&old.Path, &ret.Path)
+	tr.Translate(&old.User, &ret.User)
+	return
+}
+
+func translatePartition(old old_types.Partition) (ret types.Partition) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.GUID, &ret.GUID)
+	tr.Translate(&old.Label, &ret.Label)
+	tr.Translate(&old.Number, &ret.Number)
+	tr.Translate(&old.Resize, &ret.Resize)
+	tr.Translate(&old.ShouldExist, &ret.ShouldExist)
+	tr.Translate(&old.SizeMiB, &ret.SizeMiB)
+	tr.Translate(&old.StartMiB, &ret.StartMiB)
+	tr.Translate(&old.TypeGUID, &ret.TypeGUID)
+	tr.Translate(&old.WipePartitionEntry, &ret.WipePartitionEntry)
+	return
+}
+
+// translateRaid is needed because Raid gained Assemble in 3.5; the default
+// translator only handles structs with identical fields.
+func translateRaid(old old_types.Raid) (ret types.Raid) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.Devices, &ret.Devices)
+	tr.Translate(&old.Level, &ret.Level)
+	tr.Translate(&old.Name, &ret.Name)
+	tr.Translate(&old.Options, &ret.Options)
+	tr.Translate(&old.Spares, &ret.Spares)
+	return
+}
+
 func Translate(old old_types.Config) (ret types.Config) {
 	tr := translate.NewTranslator()
 	tr.AddCustomTranslator(translateIgnition)
-	tr.Translate(&old, &ret)
+	tr.AddCustomTranslator(translateDirectoryEmbedded1)
+	tr.AddCustomTranslator(translateFileEmbedded1)
+	tr.AddCustomTranslator(translateFilesystem)
+	tr.AddCustomTranslator(translateNode)
+	tr.AddCustomTranslator(translatePartition)
+	tr.AddCustomTranslator(translateRaid)
+	tr.Translate(&old.Ignition, &ret.Ignition)
+	tr.Translate(&old.KernelArguments, &ret.KernelArguments)
+	tr.Translate(&old.Passwd, &ret.Passwd)
+	tr.Translate(&old.Storage, &ret.Storage)
+	tr.Translate(&old.Systemd, &ret.Systemd)
 	return
 }
@@ -0,0 +1,52 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"strings"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func (b BootEntry) Key() string {
+	return b.Label
+}
+
+func (b BootEntry) Validate(c path.ContextPath) (r report.Report) {
+	if b.Label == "" {
+		r.AddOnError(c.Append("label"), errors.ErrEfiLabelRequired)
+	}
+	if b.Device == nil {
+		r.AddOnError(c.Append("device"), errors.ErrDiskDeviceRequired)
+	} else {
+		r.AddOnError(c.Append("device"), validatePath(*b.Device))
+	}
+	r.AddOnError(c.Append("loader"), b.validateLoader())
+	return
+}
+
+func (b BootEntry) validateLoader() error {
+	if util.NilOrEmpty(b.Loader) {
+		return errors.ErrEfiLoaderRequired
+	}
+	if !strings.HasPrefix(*b.Loader, "/") && !strings.HasPrefix(*b.Loader, `\`) {
+		return errors.ErrEfiLoaderRelative
+	}
+	return nil
+}
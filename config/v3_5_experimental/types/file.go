@@ -15,6 +15,8 @@
 package types
 
 import (
+	"strings"
+
 	"github.com/coreos/ignition/v2/config/shared/errors"
 	"github.com/coreos/ignition/v2/config/util"
 
@@ -25,7 +27,42 @@ import (
 func (f File) Validate(c path.ContextPath) (r report.Report) {
 	r.Merge(f.Node.Validate(c))
 	r.AddOnError(c.Append("mode"), validateMode(f.Mode))
+	r.AddOnWarn(c.Append("mode"), validateWorldWritable(f.Mode, false, f.AcknowledgeInsecureMode))
+	r.AddOnWarn(c.Append("mode"), validateSetuidFetched(f.Mode, f.AcknowledgeInsecureMode, append([]Resource{f.Contents}, f.Append...)...))
 	r.AddOnError(c.Append("overwrite"), f.validateOverwrite())
+	r.Merge(f.validateDevice(c))
+	return
+}
+
+func (f File) validateDevice(c path.ContextPath) (r report.Report) {
+	if !util.IsTrue(f.Device) {
+		return
+	}
+	if !strings.HasPrefix(f.Path, "/dev/") {
+		r.AddOnError(c.Append("path"), errors.ErrDevicePathNotInDev)
+	}
+	if f.Contents.Source == nil {
+		r.AddOnError(c.Append("contents", "source"), errors.ErrDeviceAndNilSource)
+	}
+	if util.IsTrue(f.Overwrite) {
+		r.AddOnError(c.Append("overwrite"), errors.ErrDeviceAndOverwrite)
+	}
+	if len(f.Append) > 0 {
+		r.AddOnError(c.Append("append"), errors.ErrDeviceAndAppend)
+	}
+	// the device node belongs to the running system, not the target root
+	if f.Mode != nil {
+		r.AddOnError(c.Append("mode"), errors.ErrDeviceAndMode)
+	}
+	if f.User != (NodeUser{}) {
+		r.AddOnError(c.Append("user"), errors.ErrDeviceAndOwner)
+	}
+	if f.Group != (NodeGroup{}) {
+		r.AddOnError(c.Append("group"), errors.ErrDeviceAndOwner)
+	}
+	if f.OnConflict != nil {
+		r.AddOnError(c.Append("onConflict"), errors.ErrDeviceAndOnConflict)
+	}
 	return
 }
 
@@ -15,10 +15,14 @@
 package types
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
 	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
 )
 
 func TestFileValidateOverwrite(t *testing.T) {
@@ -74,6 +78,159 @@ func TestFileValidateOverwrite(t *testing.T) {
 	}
 }
 
+func TestFileValidateDevice(t *testing.T) {
+	tests := []struct {
+		in  File
+		out error
+		at  path.ContextPath
+	}{
+		{
+			in: File{
+				FileEmbedded1: FileEmbedded1{
+					Device: util.BoolToPtr(false),
+				},
+			},
+		},
+		{
+			in: File{
+				Node: Node{
+					Path: "/dev/sda",
+				},
+				FileEmbedded1: FileEmbedded1{
+					Device: util.BoolToPtr(true),
+					Contents: Resource{
+						Source: util.StrToPtr("http://example.com/disk.img"),
+					},
+				},
+			},
+		},
+		{
+			in: File{
+				Node: Node{
+					Path: "/dev/sda",
+				},
+				FileEmbedded1: FileEmbedded1{
+					Device: util.BoolToPtr(true),
+				},
+			},
+			out: errors.ErrDeviceAndNilSource,
+			at:  path.New("json", "contents", "source"),
+		},
+		{
+			in: File{
+				Node: Node{
+					Path:      "/dev/sda",
+					Overwrite: util.BoolToPtr(true),
+				},
+				FileEmbedded1: FileEmbedded1{
+					Device: util.BoolToPtr(true),
+					Contents: Resource{
+						Source: util.StrToPtr("http://example.com/disk.img"),
+					},
+				},
+			},
+			out: errors.ErrDeviceAndOverwrite,
+			at:  path.New("json", "overwrite"),
+		},
+		{
+			in: File{
+				Node: Node{
+					Path: "/dev/sda",
+				},
+				FileEmbedded1: FileEmbedded1{
+					Device: util.BoolToPtr(true),
+					Contents: Resource{
+						Source: util.StrToPtr("http://example.com/disk.img"),
+					},
+					Append: []Resource{
+						{
+							Source: util.StrToPtr("http://example.com/more.img"),
+						},
+					},
+				},
+			},
+			out: errors.ErrDeviceAndAppend,
+			at:  path.New("json", "append"),
+		},
+		{
+			in: File{
+				Node: Node{
+					Path: "/var/disk.img",
+				},
+				FileEmbedded1: FileEmbedded1{
+					Device: util.BoolToPtr(true),
+					Contents: Resource{
+						Source: util.StrToPtr("http://example.com/disk.img"),
+					},
+				},
+			},
+			out: errors.ErrDevicePathNotInDev,
+			at:  path.New("json", "path"),
+		},
+		{
+			in: File{
+				Node: Node{
+					Path: "/dev/sda",
+				},
+				FileEmbedded1: FileEmbedded1{
+					Device: util.BoolToPtr(true),
+					Contents: Resource{
+						Source: util.StrToPtr("http://example.com/disk.img"),
+					},
+					Mode: util.IntToPtr(0600),
+				},
+			},
+			out: errors.ErrDeviceAndMode,
+			at:  path.New("json", "mode"),
+		},
+		{
+			in: File{
+				Node: Node{
+					Path: "/dev/sda",
+					User: NodeUser{
+						Name: util.StrToPtr("core"),
+					},
+				},
+				FileEmbedded1: FileEmbedded1{
+					Device: util.BoolToPtr(true),
+					Contents: Resource{
+						Source: util.StrToPtr("http://example.com/disk.img"),
+					},
+				},
+			},
+			out: errors.ErrDeviceAndOwner,
+			at:  path.New("json", "user"),
+		},
+		{
+			in: File{
+				Node: Node{
+					Path: "/dev/sda",
+					Group: NodeGroup{
+						ID: util.IntToPtr(6),
+					},
+				},
+				FileEmbedded1: FileEmbedded1{
+					Device: util.BoolToPtr(true),
+					Contents: Resource{
+						Source: util.StrToPtr("http://example.com/disk.img"),
+					},
+				},
+			},
+			out: errors.ErrDeviceAndOwner,
+			at:  path.New("json", "group"),
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.validateDevice(path.New("json"))
+		expected := report.Report{}
+		expected.AddOnError(test.at, test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, expected, r)
+		}
+	}
+}
+
 func TestFileContentsValidate(t *testing.T) {
 	tests := []struct {
 		in  Resource
@@ -38,9 +38,18 @@ func (f Filesystem) Validate(c path.ContextPath) (r report.Report) {
 	r.AddOnError(c.Append("device"), validatePath(f.Device))
 	r.AddOnError(c.Append("format"), f.validateFormat())
 	r.AddOnError(c.Append("label"), f.validateLabel())
+	r.AddOnError(c.Append("fsckPass"), f.validateFsckPass())
+	if f.EspMirror.IsPresent() {
+		r.AddOnError(c.Append("format"), f.validateEspMirrorFormat())
+		r.AddOnError(c.Append("espMirror", "source"), f.validateEspMirrorSource())
+	}
 	return
 }
 
+func (e EspMirror) IsPresent() bool {
+	return util.NotEmpty(e.Source) || util.IsTrue(e.BootEntries)
+}
+
 func (f Filesystem) validatePath() error {
 	return validatePathNilOK(f.Path)
 }
@@ -65,6 +74,39 @@ func (f Filesystem) validateFormat() error {
 	return nil
 }
 
+func (f Filesystem) validateFsckPass() error {
+	if f.FsckPass == nil || *f.FsckPass == 0 {
+		return nil
+	}
+	if *f.FsckPass < 0 || *f.FsckPass > 2 {
+		return errors.ErrFsckPassInvalid
+	}
+	if util.NilOrEmpty(f.Format) || *f.Format == "swap" || *f.Format == "none" {
+		return errors.ErrFsckPassNeedsFormat
+	}
+	return nil
+}
+
+func (f Filesystem) validateEspMirrorFormat() error {
+	if util.NilOrEmpty(f.Format) || *f.Format != "vfat" {
+		return errors.ErrEspMirrorNeedsVfat
+	}
+	return nil
+}
+
+func (f Filesystem) validateEspMirrorSource() error {
+	if util.NilOrEmpty(f.EspMirror.Source) {
+		return errors.ErrEspMirrorSourceRequired
+	}
+	if err := validatePath(*f.EspMirror.Source); err != nil {
+		return err
+	}
+	if *f.EspMirror.Source == f.Device {
+		return errors.ErrEspMirrorSourceIsDevice
+	}
+	return nil
+}
+
 func (f Filesystem) validateLabel() error {
 	if util.NilOrEmpty(f.Label) {
 		return nil
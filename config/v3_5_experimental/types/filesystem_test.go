@@ -111,6 +111,98 @@ func TestFilesystemValidatePath(t *testing.T) {
 	}
 }
 
+func TestFilesystemValidateFsckPass(t *testing.T) {
+	tests := []struct {
+		in  Filesystem
+		out error
+	}{
+		{
+			Filesystem{Format: util.StrToPtr("ext4")},
+			nil,
+		},
+		{
+			Filesystem{Format: util.StrToPtr("ext4"), FsckPass: util.IntToPtr(0)},
+			nil,
+		},
+		{
+			Filesystem{Format: util.StrToPtr("xfs"), FsckPass: util.IntToPtr(1)},
+			nil,
+		},
+		{
+			Filesystem{Format: util.StrToPtr("vfat"), FsckPass: util.IntToPtr(2)},
+			nil,
+		},
+		{
+			Filesystem{Format: util.StrToPtr("ext4"), FsckPass: util.IntToPtr(3)},
+			errors.ErrFsckPassInvalid,
+		},
+		{
+			Filesystem{Format: util.StrToPtr("ext4"), FsckPass: util.IntToPtr(-1)},
+			errors.ErrFsckPassInvalid,
+		},
+		{
+			Filesystem{Format: util.StrToPtr("swap"), FsckPass: util.IntToPtr(0)},
+			nil,
+		},
+		{
+			Filesystem{Format: util.StrToPtr("swap"), FsckPass: util.IntToPtr(2)},
+			errors.ErrFsckPassNeedsFormat,
+		},
+		{
+			Filesystem{Format: util.StrToPtr("none"), FsckPass: util.IntToPtr(1)},
+			errors.ErrFsckPassNeedsFormat,
+		},
+		{
+			Filesystem{FsckPass: util.IntToPtr(1)},
+			errors.ErrFsckPassNeedsFormat,
+		},
+	}
+
+	for i, test := range tests {
+		err := test.in.validateFsckPass()
+		if test.out != err {
+			t.Errorf("#%d: bad error: want %v, got %v", i, test.out, err)
+		}
+	}
+}
+
+func TestFilesystemValidateEspMirror(t *testing.T) {
+	tests := []struct {
+		in     Filesystem
+		format error
+		source error
+	}{
+		{
+			in: Filesystem{Device: "/dev/sdb1", Format: util.StrToPtr("vfat"), EspMirror: EspMirror{Source: util.StrToPtr("/dev/sda1")}},
+		},
+		{
+			in:     Filesystem{Device: "/dev/sdb1", Format: util.StrToPtr("ext4"), EspMirror: EspMirror{Source: util.StrToPtr("/dev/sda1")}},
+			format: errors.ErrEspMirrorNeedsVfat,
+		},
+		{
+			in:     Filesystem{Device: "/dev/sdb1", Format: util.StrToPtr("vfat"), EspMirror: EspMirror{BootEntries: util.BoolToPtr(true)}},
+			source: errors.ErrEspMirrorSourceRequired,
+		},
+		{
+			in:     Filesystem{Device: "/dev/sdb1", Format: util.StrToPtr("vfat"), EspMirror: EspMirror{Source: util.StrToPtr("sda1")}},
+			source: errors.ErrPathRelative,
+		},
+		{
+			in:     Filesystem{Device: "/dev/sdb1", Format: util.StrToPtr("vfat"), EspMirror: EspMirror{Source: util.StrToPtr("/dev/sdb1")}},
+			source: errors.ErrEspMirrorSourceIsDevice,
+		},
+	}
+
+	for i, test := range tests {
+		if err := test.in.validateEspMirrorFormat(); test.format != err {
+			t.Errorf("#%d: bad format error: want %v, got %v", i, test.format, err)
+		}
+		if err := test.in.validateEspMirrorSource(); test.source != err {
+			t.Errorf("#%d: bad source error: want %v, got %v", i, test.source, err)
+		}
+	}
+}
+
 func TestLabelValidate(t *testing.T) {
 	type in struct {
 		filesystem Filesystem
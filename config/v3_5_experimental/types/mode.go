@@ -15,7 +15,10 @@
 package types
 
 import (
+	"net/url"
+
 	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
 )
 
 func validateMode(m *int) error {
@@ -24,3 +27,38 @@ func validateMode(m *int) error {
 	}
 	return nil
 }
+
+// validateWorldWritable warns if m is writable by everyone, unless it's a
+// directory with the sticky bit set, such as /tmp.
+func validateWorldWritable(m *int, dir bool, acknowledged *bool) error {
+	if m == nil || util.IsTrue(acknowledged) || *m&0002 == 0 {
+		return nil
+	}
+	if dir && *m&01000 != 0 {
+		return nil
+	}
+	return errors.ErrModeWorldWritable
+}
+
+// validateSetuidFetched warns if m is setuid or setgid and any of the
+// contents are fetched from a remote source rather than embedded in the
+// config.
+func validateSetuidFetched(m *int, acknowledged *bool, contents ...Resource) error {
+	if m == nil || util.IsTrue(acknowledged) || *m&06000 == 0 {
+		return nil
+	}
+	for _, c := range contents {
+		if c.Source == nil {
+			continue
+		}
+		u, err := url.Parse(*c.Source)
+		if err != nil {
+			// reported elsewhere
+			continue
+		}
+		if u.Scheme != "" && u.Scheme != "data" {
+			return errors.ErrModeSetuidFetched
+		}
+	}
+	return nil
+}
@@ -60,3 +60,51 @@ func TestModeValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestModeValidateWorldWritable(t *testing.T) {
+	tests := []struct {
+		mode         *int
+		dir          bool
+		acknowledged *bool
+		out          error
+	}{
+		{nil, false, nil, nil},
+		{util.IntToPtr(0644), false, nil, nil},
+		{util.IntToPtr(0666), false, nil, errors.ErrModeWorldWritable},
+		{util.IntToPtr(0666), false, util.BoolToPtr(true), nil},
+		{util.IntToPtr(0777), true, nil, errors.ErrModeWorldWritable},
+		{util.IntToPtr(01777), true, nil, nil},
+		{util.IntToPtr(01777), false, nil, errors.ErrModeWorldWritable},
+	}
+
+	for i, test := range tests {
+		err := validateWorldWritable(test.mode, test.dir, test.acknowledged)
+		if !reflect.DeepEqual(test.out, err) {
+			t.Errorf("#%d: bad err: want %v, got %v", i, test.out, err)
+		}
+	}
+}
+
+func TestModeValidateSetuidFetched(t *testing.T) {
+	tests := []struct {
+		mode         *int
+		acknowledged *bool
+		contents     []Resource
+		out          error
+	}{
+		{nil, nil, []Resource{{Source: util.StrToPtr("https://example.com/bin")}}, nil},
+		{util.IntToPtr(0755), nil, []Resource{{Source: util.StrToPtr("https://example.com/bin")}}, nil},
+		{util.IntToPtr(04755), nil, []Resource{{}}, nil},
+		{util.IntToPtr(04755), nil, []Resource{{Source: util.StrToPtr("data:,hello")}}, nil},
+		{util.IntToPtr(04755), nil, []Resource{{Source: util.StrToPtr("https://example.com/bin")}}, errors.ErrModeSetuidFetched},
+		{util.IntToPtr(02755), nil, []Resource{{}, {Source: util.StrToPtr("s3://bucket/bin")}}, errors.ErrModeSetuidFetched},
+		{util.IntToPtr(04755), util.BoolToPtr(true), []Resource{{Source: util.StrToPtr("https://example.com/bin")}}, nil},
+	}
+
+	for i, test := range tests {
+		err := validateSetuidFetched(test.mode, test.acknowledged, test.contents...)
+		if !reflect.DeepEqual(test.out, err) {
+			t.Errorf("#%d: bad err: want %v, got %v", i, test.out, err)
+		}
+	}
+}
@@ -30,9 +30,26 @@ func (n Node) Key() string {
 
 func (n Node) Validate(c vpath.ContextPath) (r report.Report) {
 	r.AddOnError(c.Append("path"), validatePath(n.Path))
+	r.AddOnError(c.Append("onConflict"), n.validateOnConflict())
 	return
 }
 
+func (n Node) validateOnConflict() error {
+	if n.OnConflict == nil {
+		return nil
+	}
+	switch *n.OnConflict {
+	case "fail", "keep":
+		if util.IsTrue(n.Overwrite) {
+			return errors.ErrOverwriteAndOnConflict
+		}
+	case "replace":
+	default:
+		return errors.ErrInvalidOnConflict
+	}
+	return nil
+}
+
 func (n Node) Depth() int {
 	count := 0
 	for p := path.Clean(string(n.Path)); p != "/"; count++ {
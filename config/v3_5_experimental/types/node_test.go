@@ -99,3 +99,52 @@ func TestNodeValidateGroup(t *testing.T) {
 		}
 	}
 }
+
+func TestNodeValidateOnConflict(t *testing.T) {
+	tests := []struct {
+		in  Node
+		out error
+	}{
+		{
+			Node{Path: "/foo"},
+			nil,
+		},
+		{
+			Node{Path: "/foo", OnConflict: util.StrToPtr("fail")},
+			nil,
+		},
+		{
+			Node{Path: "/foo", OnConflict: util.StrToPtr("replace")},
+			nil,
+		},
+		{
+			Node{Path: "/foo", OnConflict: util.StrToPtr("keep")},
+			nil,
+		},
+		{
+			Node{Path: "/foo", OnConflict: util.StrToPtr("replace"), Overwrite: util.BoolToPtr(true)},
+			nil,
+		},
+		{
+			Node{Path: "/foo", OnConflict: util.StrToPtr("keep"), Overwrite: util.BoolToPtr(true)},
+			errors.ErrOverwriteAndOnConflict,
+		},
+		{
+			Node{Path: "/foo", OnConflict: util.StrToPtr("fail"), Overwrite: util.BoolToPtr(true)},
+			errors.ErrOverwriteAndOnConflict,
+		},
+		{
+			Node{Path: "/foo", OnConflict: util.StrToPtr("overwrite")},
+			errors.ErrInvalidOnConflict,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Validate(path.ContextPath{})
+		expected := report.Report{}
+		expected.AddOnError(path.New("", "onConflict"), test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v got %v", i, test.out, r)
+		}
+	}
+}
@@ -16,11 +16,19 @@ package types
 
 import (
 	"path"
+	"strings"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
 	"github.com/coreos/ignition/v2/config/util"
 )
 
+const (
+	// maxPathLength and maxPathComponentLength are Linux's PATH_MAX
+	// (less the terminating NUL) and NAME_MAX.
+	maxPathLength          = 4095
+	maxPathComponentLength = 255
+)
+
 func validatePath(p string) error {
 	if p == "" {
 		return errors.ErrNoPath
@@ -31,6 +39,17 @@ func validatePath(p string) error {
 	if path.Clean(p) != p {
 		return errors.ErrDirtyPath
 	}
+	if strings.ContainsRune(p, 0) {
+		return errors.ErrPathContainsNUL
+	}
+	if len(p) > maxPathLength {
+		return errors.ErrPathTooLong
+	}
+	for _, component := range strings.Split(p, "/") {
+		if len(component) > maxPathComponentLength {
+			return errors.ErrPathComponentTooLong
+		}
+	}
 	return nil
 }
 
@@ -16,6 +16,7 @@ package types
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
@@ -46,6 +47,30 @@ func TestPathValidate(t *testing.T) {
 			"relative/path",
 			errors.ErrPathRelative,
 		},
+		{
+			"/a/file\nwith a newline/and/ünicøde",
+			nil,
+		},
+		{
+			"/nul\x00",
+			errors.ErrPathContainsNUL,
+		},
+		{
+			strings.Repeat("/"+strings.Repeat("a", 255), 15) + "/" + strings.Repeat("b", 254),
+			nil,
+		},
+		{
+			strings.Repeat("/"+strings.Repeat("a", 255), 15) + "/" + strings.Repeat("b", 255),
+			errors.ErrPathTooLong,
+		},
+		{
+			strings.Repeat("/a", 2047),
+			nil,
+		},
+		{
+			"/" + strings.Repeat("a", 256),
+			errors.ErrPathComponentTooLong,
+		},
 	}
 
 	for i, test := range tests {
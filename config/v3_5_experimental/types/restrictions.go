@@ -0,0 +1,129 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"path"
+	"strings"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	vpath "github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+// Where the files stage writes units, drop-ins, and presets; these match
+// SystemdUnitsPath, SystemdDropinsPath, and PresetPath in
+// internal/exec/util, which can't be imported here.
+const (
+	systemdUnitsPath  = "/etc/systemd/system"
+	systemdPresetPath = "/etc/systemd/system-preset/20-ignition.preset"
+)
+
+func (cr ConfigRestrictions) Validate(c vpath.ContextPath) (r report.Report) {
+	for i, p := range cr.AllowedPaths {
+		r.AddOnError(c.Append("allowedPaths", i), validatePath(string(p)))
+	}
+	return
+}
+
+// Check reports every part of the rendered child config that cr doesn't
+// allow it to set. The returned report uses paths relative to child.
+func (cr ConfigRestrictions) Check(child Config) (r report.Report) {
+	c := vpath.New("json")
+	if len(cr.AllowedPaths) > 0 {
+		for i, f := range child.Storage.Files {
+			r.AddOnError(c.Append("storage", "files", i, "path"), cr.checkPath(f.Path, errors.ErrRestrictedPath))
+		}
+		for i, d := range child.Storage.Directories {
+			r.AddOnError(c.Append("storage", "directories", i, "path"), cr.checkPath(d.Path, errors.ErrRestrictedPath))
+		}
+		for i, l := range child.Storage.Links {
+			r.AddOnError(c.Append("storage", "links", i, "path"), cr.checkPath(l.Path, errors.ErrRestrictedPath))
+			if util.NotEmpty(l.Target) {
+				// otherwise a file could be written through the link
+				target := *l.Target
+				if !path.IsAbs(target) {
+					target = path.Join(path.Dir(l.Path), target)
+				}
+				r.AddOnError(c.Append("storage", "links", i, "target"), cr.checkPath(target, errors.ErrRestrictedLinkTarget))
+			}
+		}
+		for i, u := range child.Systemd.Units {
+			// units are written, masked, or unmasked at the unit path
+			if u.Contents != nil || u.Mask != nil {
+				r.AddOnError(c.Append("systemd", "units", i, "name"), cr.checkPath(path.Join(systemdUnitsPath, u.Name), errors.ErrRestrictedPath))
+			}
+			for j, d := range u.Dropins {
+				if d.Contents != nil {
+					r.AddOnError(c.Append("systemd", "units", i, "dropins", j, "name"), cr.checkPath(path.Join(systemdUnitsPath, u.Name+".d", d.Name), errors.ErrRestrictedPath))
+				}
+			}
+			if u.Enabled != nil {
+				r.AddOnError(c.Append("systemd", "units", i, "enabled"), cr.checkPath(systemdPresetPath, errors.ErrRestrictedPath))
+			}
+		}
+	}
+	if util.IsTrue(cr.DenyDisks) {
+		if len(child.Storage.Disks) > 0 {
+			r.AddOnError(c.Append("storage", "disks"), errors.ErrRestrictedDisks)
+		}
+		if len(child.Storage.Raid) > 0 {
+			r.AddOnError(c.Append("storage", "raid"), errors.ErrRestrictedDisks)
+		}
+		if len(child.Storage.Luks) > 0 {
+			r.AddOnError(c.Append("storage", "luks"), errors.ErrRestrictedDisks)
+		}
+		if len(child.Storage.Filesystems) > 0 {
+			r.AddOnError(c.Append("storage", "filesystems"), errors.ErrRestrictedDisks)
+		}
+	}
+	if util.IsTrue(cr.DenyPasswd) {
+		if len(child.Passwd.Users) > 0 {
+			r.AddOnError(c.Append("passwd", "users"), errors.ErrRestrictedPasswd)
+		}
+		if len(child.Passwd.Groups) > 0 {
+			r.AddOnError(c.Append("passwd", "groups"), errors.ErrRestrictedPasswd)
+		}
+	}
+	if util.IsTrue(cr.DenySystemd) && len(child.Systemd.Units) > 0 {
+		r.AddOnError(c.Append("systemd", "units"), errors.ErrRestrictedSystemd)
+	}
+	if util.IsTrue(cr.DenyBoot) {
+		if len(child.KernelArguments.ShouldExist) > 0 {
+			r.AddOnError(c.Append("kernelArguments", "shouldExist"), errors.ErrRestrictedBoot)
+		}
+		if len(child.KernelArguments.ShouldNotExist) > 0 {
+			r.AddOnError(c.Append("kernelArguments", "shouldNotExist"), errors.ErrRestrictedBoot)
+		}
+		if len(child.Efi.BootEntries) > 0 {
+			r.AddOnError(c.Append("efi", "bootEntries"), errors.ErrRestrictedBoot)
+		}
+	}
+	return
+}
+
+// checkPath returns err unless p is at or below one of the allowed paths.
+func (cr ConfigRestrictions) checkPath(p string, err error) error {
+	p = path.Clean(p)
+	for _, allowed := range cr.AllowedPaths {
+		prefix := path.Clean(string(allowed))
+		if p == prefix || strings.HasPrefix(p, strings.TrimSuffix(prefix, "/")+"/") {
+			return nil
+		}
+	}
+	return err
+}
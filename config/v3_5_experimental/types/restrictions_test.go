@@ -0,0 +1,150 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func TestConfigRestrictionsCheck(t *testing.T) {
+	child := Config{
+		Efi: Efi{
+			BootEntries: []BootEntry{{Label: "tenant"}},
+		},
+		KernelArguments: KernelArguments{
+			ShouldExist: []KernelArgument{"init=/bin/sh"},
+		},
+		Passwd: Passwd{
+			Users: []PasswdUser{{Name: "tenant"}},
+		},
+		Storage: Storage{
+			Disks: []Disk{{Device: "/dev/vdb"}},
+			Files: []File{
+				{Node: Node{Path: "/var/lib/tenant/config"}},
+				{Node: Node{Path: "/var/lib/tenantfoo"}},
+			},
+			Directories: []Directory{{Node: Node{Path: "/var/lib/tenant"}}},
+			Links: []Link{
+				{
+					Node:          Node{Path: "/var/lib/tenant/link"},
+					LinkEmbedded1: LinkEmbedded1{Target: util.StrToPtr("data")},
+				},
+				{
+					Node:          Node{Path: "/var/lib/tenant/escape"},
+					LinkEmbedded1: LinkEmbedded1{Target: util.StrToPtr("../../../etc")},
+				},
+			},
+		},
+		Systemd: Systemd{
+			Units: []Unit{
+				{Name: "tenant.service"},
+				{
+					Name:     "root.service",
+					Contents: util.StrToPtr("[Service]"),
+					Dropins:  []Dropin{{Name: "override.conf", Contents: util.StrToPtr("[Service]")}},
+					Enabled:  util.BoolToPtr(true),
+				},
+				{Name: "sshd.service", Mask: util.BoolToPtr(true)},
+			},
+		},
+	}
+
+	tests := []struct {
+		in  ConfigRestrictions
+		out report.Report
+	}{
+		// no restrictions
+		{},
+		{
+			in: ConfigRestrictions{
+				AllowedPaths: []AllowedPath{"/var/lib/tenant"},
+			},
+			out: func() (r report.Report) {
+				r.AddOnError(path.New("json", "storage", "files", 1, "path"), errors.ErrRestrictedPath)
+				r.AddOnError(path.New("json", "storage", "links", 1, "target"), errors.ErrRestrictedLinkTarget)
+				r.AddOnError(path.New("json", "systemd", "units", 1, "name"), errors.ErrRestrictedPath)
+				r.AddOnError(path.New("json", "systemd", "units", 1, "dropins", 0, "name"), errors.ErrRestrictedPath)
+				r.AddOnError(path.New("json", "systemd", "units", 1, "enabled"), errors.ErrRestrictedPath)
+				r.AddOnError(path.New("json", "systemd", "units", 2, "name"), errors.ErrRestrictedPath)
+				return
+			}(),
+		},
+		{
+			in: ConfigRestrictions{
+				AllowedPaths: []AllowedPath{"/var/lib/tenant", "/etc/systemd/system"},
+			},
+			out: func() (r report.Report) {
+				r.AddOnError(path.New("json", "storage", "files", 1, "path"), errors.ErrRestrictedPath)
+				r.AddOnError(path.New("json", "storage", "links", 1, "target"), errors.ErrRestrictedLinkTarget)
+				r.AddOnError(path.New("json", "systemd", "units", 1, "enabled"), errors.ErrRestrictedPath)
+				return
+			}(),
+		},
+		{
+			in: ConfigRestrictions{
+				AllowedPaths: []AllowedPath{"/"},
+			},
+		},
+		{
+			in: ConfigRestrictions{
+				DenyDisks:   util.BoolToPtr(true),
+				DenyPasswd:  util.BoolToPtr(true),
+				DenySystemd: util.BoolToPtr(true),
+			},
+			out: func() (r report.Report) {
+				r.AddOnError(path.New("json", "storage", "disks"), errors.ErrRestrictedDisks)
+				r.AddOnError(path.New("json", "passwd", "users"), errors.ErrRestrictedPasswd)
+				r.AddOnError(path.New("json", "systemd", "units"), errors.ErrRestrictedSystemd)
+				return
+			}(),
+		},
+		{
+			in: ConfigRestrictions{
+				DenyBoot: util.BoolToPtr(true),
+			},
+			out: func() (r report.Report) {
+				r.AddOnError(path.New("json", "kernelArguments", "shouldExist"), errors.ErrRestrictedBoot)
+				r.AddOnError(path.New("json", "efi", "bootEntries"), errors.ErrRestrictedBoot)
+				return
+			}(),
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Check(child)
+		if !reflect.DeepEqual(test.out, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, test.out, r)
+		}
+	}
+}
+
+func TestConfigRestrictionsValidate(t *testing.T) {
+	in := ConfigRestrictions{
+		AllowedPaths: []AllowedPath{"/var/lib/tenant", "var/lib/other"},
+	}
+	expected := report.Report{}
+	expected.AddOnError(path.New("", "allowedPaths", 1), errors.ErrPathRelative)
+	r := in.Validate(path.New(""))
+	if !reflect.DeepEqual(expected, r) {
+		t.Errorf("bad report: want %v, got %v", expected, r)
+	}
+}
@@ -2,6 +2,14 @@ package types
 
 // generated by "schematyper --package=types config/v3_5_experimental/schema/ignition.json -o config/v3_5_experimental/types/schema.go --root-type=Config" -- DO NOT EDIT
 
+type AllowedPath string
+
+type BootEntry struct {
+	Device *string `json:"device,omitempty"`
+	Label  string  `json:"label"`
+	Loader *string `json:"loader,omitempty"`
+}
+
 type Clevis struct {
 	Custom    ClevisCustom `json:"custom,omitempty"`
 	Tang      []Tang       `json:"tang,omitempty"`
@@ -16,6 +24,7 @@ type ClevisCustom struct {
 }
 
 type Config struct {
+	Efi             Efi             `json:"efi,omitempty"`
 	Ignition        Ignition        `json:"ignition"`
 	KernelArguments KernelArguments `json:"kernelArguments,omitempty"`
 	Passwd          Passwd          `json:"passwd,omitempty"`
@@ -23,6 +32,14 @@ type Config struct {
 	Systemd         Systemd         `json:"systemd,omitempty"`
 }
 
+type ConfigRestrictions struct {
+	AllowedPaths []AllowedPath `json:"allowedPaths,omitempty"`
+	DenyBoot     *bool         `json:"denyBoot,omitempty"`
+	DenyDisks    *bool         `json:"denyDisks,omitempty"`
+	DenyPasswd   *bool         `json:"denyPasswd,omitempty"`
+	DenySystemd  *bool         `json:"denySystemd,omitempty"`
+}
+
 type Device string
 
 type Directory struct {
@@ -31,7 +48,8 @@ type Directory struct {
 }
 
 type DirectoryEmbedded1 struct {
-	Mode *int `json:"mode,omitempty"`
+	AcknowledgeInsecureMode *bool `json:"acknowledgeInsecureMode,omitempty"`
+	Mode                    *int  `json:"mode,omitempty"`
 }
 
 type Disk struct {
@@ -45,20 +63,33 @@ type Dropin struct {
 	Name     string  `json:"name"`
 }
 
+type Efi struct {
+	BootEntries []BootEntry `json:"bootEntries,omitempty"`
+}
+
+type EspMirror struct {
+	BootEntries *bool   `json:"bootEntries,omitempty"`
+	Source      *string `json:"source,omitempty"`
+}
+
 type File struct {
 	Node
 	FileEmbedded1
 }
 
 type FileEmbedded1 struct {
-	Append   []Resource `json:"append,omitempty"`
-	Contents Resource   `json:"contents,omitempty"`
-	Mode     *int       `json:"mode,omitempty"`
+	AcknowledgeInsecureMode *bool      `json:"acknowledgeInsecureMode,omitempty"`
+	Append                  []Resource `json:"append,omitempty"`
+	Contents                Resource   `json:"contents,omitempty"`
+	Device                  *bool      `json:"device,omitempty"`
+	Mode                    *int       `json:"mode,omitempty"`
 }
 
 type Filesystem struct {
 	Device         string             `json:"device"`
+	EspMirror      EspMirror          `json:"espMirror,omitempty"`
 	Format         *string            `json:"format,omitempty"`
+	FsckPass       *int               `json:"fsckPass,omitempty"`
 	Label          *string            `json:"label,omitempty"`
 	MountOptions   []MountOption      `json:"mountOptions,omitempty"`
 	Options        []FilesystemOption `json:"options,omitempty"`
@@ -87,8 +118,9 @@ type Ignition struct {
 }
 
 type IgnitionConfig struct {
-	Merge   []Resource `json:"merge,omitempty"`
-	Replace Resource   `json:"replace,omitempty"`
+	Merge        []Resource         `json:"merge,omitempty"`
+	Replace      Resource           `json:"replace,omitempty"`
+	Restrictions ConfigRestrictions `json:"restrictions,omitempty"`
 }
 
 type KernelArgument string
@@ -128,10 +160,11 @@ type MountOption string
 type NoProxyItem string
 
 type Node struct {
-	Group     NodeGroup `json:"group,omitempty"`
-	Overwrite *bool     `json:"overwrite,omitempty"`
-	Path      string    `json:"path"`
-	User      NodeUser  `json:"user,omitempty"`
+	Group      NodeGroup `json:"group,omitempty"`
+	OnConflict *string   `json:"onConflict,omitempty"`
+	Overwrite  *bool     `json:"overwrite,omitempty"`
+	Path       string    `json:"path"`
+	User       NodeUser  `json:"user,omitempty"`
 }
 
 type NodeGroup struct {
@@ -151,6 +184,7 @@ type Partition struct {
 	Label              *string `json:"label,omitempty"`
 	Number             int     `json:"number,omitempty"`
 	Resize             *bool   `json:"resize,omitempty"`
+	Retype             *bool   `json:"retype,omitempty"`
 	ShouldExist        *bool   `json:"shouldExist,omitempty"`
 	SizeMiB            *int    `json:"sizeMiB,omitempty"`
 	StartMiB           *int    `json:"startMiB,omitempty"`
@@ -245,6 +279,7 @@ type Tang struct {
 type Timeouts struct {
 	HTTPResponseHeaders *int `json:"httpResponseHeaders,omitempty"`
 	HTTPTotal           *int `json:"httpTotal,omitempty"`
+	ProvisioningBudget  *int `json:"provisioningBudget,omitempty"`
 }
 
 type Unit struct {
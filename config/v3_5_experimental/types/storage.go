@@ -15,8 +15,11 @@
 package types
 
 import (
+	"fmt"
 	"path"
+	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
 	"github.com/coreos/ignition/v2/config/util"
@@ -25,6 +28,13 @@ import (
 	"github.com/coreos/vcontext/report"
 )
 
+// maxGPTPartitions is the number of partition entries sgdisk creates.
+const maxGPTPartitions = 128
+
+var (
+	numberedRaidDeviceRegex = regexp.MustCompile(`^/dev/md[0-9]+$`)
+)
+
 func (s Storage) MergedKeys() map[string]string {
 	return map[string]string{
 		"Directories": "Node",
@@ -38,6 +48,7 @@ func (s Storage) Validate(c vpath.ContextPath) (r report.Report) {
 	s.validateFiles(c, &r)
 	s.validateLinks(c, &r)
 	s.validateFilesystems(c, &r)
+	s.validateDeviceReferences(c, &r)
 	return
 }
 
@@ -113,3 +124,120 @@ func (s Storage) validateFilesystems(c vpath.ContextPath, r *report.Report) {
 		}
 	}
 }
+
+// validateDeviceReferences checks that references to devices which are
+// created by Ignition itself (partitions on disks whose partition table is
+// wiped, RAID arrays, and LUKS volumes) resolve to a partition, array, or
+// volume declared in the config. Since arrays and volumes can also be
+// provided by the system, references are only checked if the config
+// declares some, and unresolved references are only warnings. Files aren't
+// checked, since they're written relative to the root filesystem rather
+// than to a device.
+func (s Storage) validateDeviceReferences(c vpath.ContextPath, r *report.Report) {
+	missingPartitions := s.undeclaredPartitions()
+	raids := make(map[string]struct{})
+	for _, md := range s.Raid {
+		raids[md.Name] = struct{}{}
+	}
+	luks := make(map[string]struct{})
+	for _, l := range s.Luks {
+		luks[l.Name] = struct{}{}
+	}
+
+	check := func(p vpath.ContextPath, device string) {
+		device = path.Clean(device)
+		if _, ok := missingPartitions[device]; ok {
+			r.AddOnWarn(p, errors.ErrUnknownPartition)
+		}
+		if name := strings.TrimPrefix(device, "/dev/md/"); name != device && len(s.Raid) > 0 {
+			if _, ok := raids[name]; !ok {
+				r.AddOnWarn(p, errors.ErrUnknownRaidDevice)
+			}
+		} else if numberedRaidDeviceRegex.MatchString(device) && len(s.Raid) > 0 {
+			r.AddOnWarn(p, errors.ErrRaidDeviceByNumber)
+		}
+		// /dev/mapper is shared with LVM and friends, so only complain
+		// if the config is managing LUKS volumes in the first place
+		if len(s.Luks) == 0 {
+			return
+		}
+		for _, prefix := range []string{"/dev/mapper/", "/dev/disk/by-id/dm-name-"} {
+			if name := strings.TrimPrefix(device, prefix); name != device {
+				if _, ok := luks[name]; !ok {
+					r.AddOnWarn(p, errors.ErrUnknownLuksDevice)
+				}
+			}
+		}
+	}
+
+	for i, md := range s.Raid {
+		for j, d := range md.Devices {
+			check(c.Append("raid", i, "devices", j), string(d))
+		}
+	}
+	for i, l := range s.Luks {
+		if util.NotEmpty(l.Device) {
+			check(c.Append("luks", i, "device"), *l.Device)
+		}
+	}
+	for i, f := range s.Filesystems {
+		if f.Device != "" {
+			check(c.Append("filesystems", i, "device"), f.Device)
+		}
+		if util.NotEmpty(f.EspMirror.Source) {
+			check(c.Append("filesystems", i, "espMirror", "source"), *f.EspMirror.Source)
+		}
+	}
+}
+
+// undeclaredPartitions returns the device paths of the partitions that
+// can't exist once Ignition has partitioned the disks: those deleted with
+// shouldExist false, and, on disks whose partition tables are wiped, those
+// whose numbers aren't declared as existing. Wiped disks with partitions
+// whose numbers are chosen at runtime are skipped.
+func (s Storage) undeclaredPartitions() map[string]struct{} {
+	missing := make(map[string]struct{})
+	for _, d := range s.Disks {
+		declared := make(map[int]struct{})
+		known := true
+		for _, p := range d.Partitions {
+			if p.ShouldExist != nil && !*p.ShouldExist {
+				continue
+			}
+			if p.Number == 0 {
+				known = false
+				continue
+			}
+			declared[p.Number] = struct{}{}
+		}
+		for _, p := range d.Partitions {
+			if p.ShouldExist != nil && !*p.ShouldExist && p.Number != 0 {
+				if _, ok := declared[p.Number]; !ok {
+					missing[partitionDevice(path.Clean(d.Device), p.Number)] = struct{}{}
+				}
+			}
+		}
+		if !util.IsTrue(d.WipeTable) || !known {
+			continue
+		}
+		for n := 1; n <= maxGPTPartitions; n++ {
+			if _, ok := declared[n]; !ok {
+				missing[partitionDevice(path.Clean(d.Device), n)] = struct{}{}
+			}
+		}
+	}
+	return missing
+}
+
+// partitionDevice returns the path of partition n of disk, following the
+// kernel's and udev's naming conventions.
+func partitionDevice(disk string, n int) string {
+	switch {
+	case strings.HasPrefix(disk, "/dev/disk/by-"):
+		return fmt.Sprintf("%s-part%d", disk, n)
+	case disk != "" && unicode.IsDigit(rune(disk[len(disk)-1])):
+		return fmt.Sprintf("%sp%d", disk, n)
+	default:
+		return fmt.Sprintf("%s%d", disk, n)
+	}
+}
@@ -346,6 +346,277 @@ func TestStorageValidateWarnings(t *testing.T) {
 			},
 			out: nil,
 		},
+		// test a filesystem on a declared RAID array returns nil
+		{
+			in: Storage{
+				Raid: []Raid{
+					{
+						Name:    "data",
+						Devices: []Device{"/dev/sda", "/dev/sdb"},
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/md/data",
+					},
+				},
+			},
+			out: nil,
+		},
+		// test a filesystem on an undeclared RAID array returns ErrUnknownRaidDevice
+		{
+			in: Storage{
+				Raid: []Raid{
+					{
+						Name:    "data",
+						Devices: []Device{"/dev/sda", "/dev/sdb"},
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/md/dat",
+					},
+				},
+			},
+			out: errors.ErrUnknownRaidDevice,
+			at:  path.New("", "filesystems", 0, "device"),
+		},
+		// test a filesystem referencing a RAID array by number returns ErrRaidDeviceByNumber
+		{
+			in: Storage{
+				Raid: []Raid{
+					{
+						Name:    "data",
+						Devices: []Device{"/dev/sda", "/dev/sdb"},
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/md127",
+					},
+				},
+			},
+			out: errors.ErrRaidDeviceByNumber,
+			at:  path.New("", "filesystems", 0, "device"),
+		},
+		// test a filesystem referencing a RAID array by number without declared arrays returns nil
+		{
+			in: Storage{
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/md127",
+					},
+				},
+			},
+			out: nil,
+		},
+		// test a LUKS volume on an undeclared RAID array returns ErrUnknownRaidDevice
+		{
+			in: Storage{
+				Raid: []Raid{
+					{
+						Name:    "other",
+						Devices: []Device{"/dev/sda", "/dev/sdb"},
+					},
+				},
+				Luks: []Luks{
+					{
+						Name:   "data",
+						Device: util.StrToPtr("/dev/md/data"),
+					},
+				},
+			},
+			out: errors.ErrUnknownRaidDevice,
+			at:  path.New("", "luks", 0, "device"),
+		},
+		// test a filesystem on a declared LUKS volume returns nil
+		{
+			in: Storage{
+				Luks: []Luks{
+					{
+						Name:   "data",
+						Device: util.StrToPtr("/dev/sda"),
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/disk/by-id/dm-name-data",
+					},
+				},
+			},
+			out: nil,
+		},
+		// test a filesystem on an undeclared LUKS volume returns ErrUnknownLuksDevice
+		{
+			in: Storage{
+				Luks: []Luks{
+					{
+						Name:   "data",
+						Device: util.StrToPtr("/dev/sda"),
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/mapper/root",
+					},
+				},
+			},
+			out: errors.ErrUnknownLuksDevice,
+			at:  path.New("", "filesystems", 0, "device"),
+		},
+		// test a filesystem on a RAID array without declared arrays returns nil
+		{
+			in: Storage{
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/md/assembled-by-the-system",
+					},
+				},
+			},
+			out: nil,
+		},
+		// test a filesystem on a declared partition of a wiped disk returns nil
+		{
+			in: Storage{
+				Disks: []Disk{
+					{
+						Device:     "/dev/nvme0n1",
+						WipeTable:  util.BoolToPtr(true),
+						Partitions: []Partition{{Number: 1}, {Number: 2}},
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/nvme0n1p2",
+					},
+				},
+			},
+			out: nil,
+		},
+		// test a filesystem on an undeclared partition of a wiped disk returns ErrUnknownPartition
+		{
+			in: Storage{
+				Disks: []Disk{
+					{
+						Device:     "/dev/disk/by-id/virtio-disk",
+						WipeTable:  util.BoolToPtr(true),
+						Partitions: []Partition{{Number: 1}},
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/disk/by-id/virtio-disk-part3",
+					},
+				},
+			},
+			out: errors.ErrUnknownPartition,
+			at:  path.New("", "filesystems", 0, "device"),
+		},
+		// test a RAID array on an undeclared partition of a wiped disk returns ErrUnknownPartition
+		{
+			in: Storage{
+				Disks: []Disk{
+					{
+						Device:    "/dev/sda",
+						WipeTable: util.BoolToPtr(true),
+					},
+				},
+				Raid: []Raid{
+					{
+						Name:    "data",
+						Devices: []Device{"/dev/sda1"},
+					},
+				},
+			},
+			out: errors.ErrUnknownPartition,
+			at:  path.New("", "raid", 0, "devices", 0),
+		},
+		// test a filesystem on a partition deleted by the config returns ErrUnknownPartition
+		{
+			in: Storage{
+				Disks: []Disk{
+					{
+						Device:     "/dev/sda",
+						Partitions: []Partition{{Number: 2, ShouldExist: util.BoolToPtr(false)}},
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/sda2",
+					},
+				},
+			},
+			out: errors.ErrUnknownPartition,
+			at:  path.New("", "filesystems", 0, "device"),
+		},
+		// test a filesystem on a deleted partition of a wiped disk returns ErrUnknownPartition
+		{
+			in: Storage{
+				Disks: []Disk{
+					{
+						Device:    "/dev/sda",
+						WipeTable: util.BoolToPtr(true),
+						Partitions: []Partition{
+							{Number: 1},
+							{Number: 2, ShouldExist: util.BoolToPtr(false)},
+						},
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/sda2",
+					},
+				},
+			},
+			out: errors.ErrUnknownPartition,
+			at:  path.New("", "filesystems", 0, "device"),
+		},
+		// test a filesystem on a partition of a disk that isn't wiped returns nil
+		{
+			in: Storage{
+				Disks: []Disk{
+					{
+						Device:     "/dev/sda",
+						Partitions: []Partition{{Number: 1}},
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/sda4",
+					},
+				},
+			},
+			out: nil,
+		},
+		// test a filesystem on a wiped disk with unnumbered partitions returns nil
+		{
+			in: Storage{
+				Disks: []Disk{
+					{
+						Device:     "/dev/sda",
+						WipeTable:  util.BoolToPtr(true),
+						Partitions: []Partition{{Label: util.StrToPtr("data")}},
+					},
+				},
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/sda1",
+					},
+				},
+			},
+			out: nil,
+		},
+		// test a filesystem under /dev/mapper without declared LUKS volumes returns nil
+		{
+			in: Storage{
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/mapper/vg-root",
+					},
+				},
+			},
+			out: nil,
+		},
 	}
 
 	for i, test := range tests {
@@ -15,6 +15,7 @@
 package types
 
 import (
+	stderrors "errors"
 	"net/url"
 	"strings"
 
@@ -28,8 +29,17 @@ import (
 func validateURL(s string) error {
 	u, err := url.Parse(s)
 	if err != nil {
+		var escapeErr url.EscapeError
+		if stderrors.As(err, &escapeErr) {
+			return errors.ErrInvalidUrlEscape
+		}
 		return errors.ErrInvalidUrl
 	}
+	if u.Scheme != "data" {
+		if err := validateURLEncoding(s, u); err != nil {
+			return err
+		}
+	}
 
 	switch u.Scheme {
 	case "http", "https", "tftp", "gs":
@@ -75,6 +85,23 @@ func validateURL(s string) error {
 	}
 }
 
+// validateURLEncoding rejects raw spaces, which url.Parse accepts in paths
+// but which would otherwise be silently encoded at fetch time, and checks
+// the percent-encoding of the parts that url.Parse leaves encoded. A "+"
+// is a literal "+" in paths and S3 object keys; spell a space as "%20".
+func validateURLEncoding(s string, u *url.URL) error {
+	if strings.Contains(s, " ") {
+		return errors.ErrUnescapedSpaceInUrl
+	}
+	if _, err := url.PathUnescape(u.Opaque); err != nil {
+		return errors.ErrInvalidUrlEscape
+	}
+	if _, err := url.QueryUnescape(u.RawQuery); err != nil {
+		return errors.ErrInvalidUrlEscape
+	}
+	return nil
+}
+
 func validateURLNilOK(s *string) error {
 	if util.NilOrEmpty(s) {
 		return nil
@@ -126,6 +126,50 @@ func TestURLValidate(t *testing.T) {
 			util.StrToPtr("gs://bucket/object"),
 			nil,
 		},
+		{
+			util.StrToPtr("http://example.com/a%20file+with%2Bplus"),
+			nil,
+		},
+		{
+			util.StrToPtr("http://example.com/a file"),
+			errors.ErrUnescapedSpaceInUrl,
+		},
+		{
+			util.StrToPtr("http://example.com/a%zzfile"),
+			errors.ErrInvalidUrlEscape,
+		},
+		{
+			util.StrToPtr("http://example.com/file?name=a%zz"),
+			errors.ErrInvalidUrlEscape,
+		},
+		{
+			util.StrToPtr("s3://bucket/a file"),
+			errors.ErrUnescapedSpaceInUrl,
+		},
+		{
+			util.StrToPtr("s3://bucket/a%2"),
+			errors.ErrInvalidUrlEscape,
+		},
+		{
+			util.StrToPtr("gs://bucket/a file"),
+			errors.ErrUnescapedSpaceInUrl,
+		},
+		{
+			util.StrToPtr("tftp://example.com/a%20file"),
+			nil,
+		},
+		{
+			util.StrToPtr("arn:aws:s3:::bucket-name/a%20file+with%2Bplus"),
+			nil,
+		},
+		{
+			util.StrToPtr("arn:aws:s3:::bucket-name/a file"),
+			errors.ErrUnescapedSpaceInUrl,
+		},
+		{
+			util.StrToPtr("arn:aws:s3:::bucket-name/a%zzfile"),
+			errors.ErrInvalidUrlEscape,
+		},
 	}
 
 	for i, test := range tests {
@@ -15,11 +15,11 @@
 package types
 
 import (
-	"crypto"
 	"encoding/hex"
 	"strings"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
 
 	"github.com/coreos/vcontext/path"
 	"github.com/coreos/vcontext/report"
@@ -52,18 +52,13 @@ func (v Verification) Validate(c path.ContextPath) (r report.Report) {
 		r.AddOnError(c, err)
 		return
 	}
-	var hash crypto.Hash
-	switch function {
-	case "sha512":
-		hash = crypto.SHA512
-	case "sha256":
-		hash = crypto.SHA256
-	default:
+	hash, ok := util.GetHashFunction(function)
+	if !ok {
 		r.AddOnError(c, errors.ErrHashUnrecognized)
 		return
 	}
 
-	if len(sum) != hex.EncodedLen(hash.Size()) {
+	if len(sum) != hex.EncodedLen(hash.Size) {
 		r.AddOnError(c, errors.ErrHashWrongSize)
 	}
 
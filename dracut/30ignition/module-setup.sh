@@ -32,6 +32,13 @@ install() {
     # (e.g. on embedded systems), so only add applications which are actually
     # present
     inst_multiple -o \
+        efibootmgr \
+        fsck \
+        fsck.btrfs \
+        fsck.ext4 \
+        fsck.fat \
+        fsck.vfat \
+        fsck.xfs \
         groupadd \
         groupdel \
         mkfs.btrfs \
@@ -30,6 +30,7 @@ import (
 	_ "github.com/coreos/ignition/v2/internal/exec/stages/mount"
 	_ "github.com/coreos/ignition/v2/internal/exec/stages/umount"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/policy"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
 	"github.com/coreos/ignition/v2/internal/util"
@@ -88,6 +89,9 @@ func Run(cfg types.Config, flags Flags, logger *log.Logger) error {
 	if err != nil {
 		return err
 	}
+	if err := policy.Check(logger, finalCfg); err != nil {
+		return err
+	}
 
 	// verify upfront if we'll need networking but we're not allowed
 	if flags.Offline {
@@ -17,6 +17,7 @@ package distro
 import (
 	"fmt"
 	"os"
+	"strconv"
 )
 
 // Distro-specific settings that can be overridden at link time with e.g.
@@ -47,6 +48,7 @@ var (
 	systemctlCmd = "systemctl"
 
 	// Filesystem tools
+	fsckCmd      = "fsck"
 	btrfsMkfsCmd = "mkfs.btrfs"
 	ext4MkfsCmd  = "mkfs.ext4"
 	swapMkfsCmd  = "mkswap"
@@ -62,6 +64,9 @@ var (
 	clevisCmd     = "clevis"
 	cryptsetupCmd = "cryptsetup"
 
+	// EFI programs
+	efibootmgrCmd = "efibootmgr"
+
 	// kargs programs
 	kargsCmd = "ignition-kargs-helper"
 
@@ -73,10 +78,14 @@ var (
 	// ".ssh/authorized_keys.d/ignition" ("true"), or to
 	// ".ssh/authorized_keys" ("false").
 	writeAuthorizedKeysFragment = "true"
+	// resultArtifactMaxSize is the size in bytes beyond which the result
+	// file and checksum file are truncated, or "0" for no limit.
+	resultArtifactMaxSize = "1048576"
 
 	// Special file paths in the real root
 	luksRealRootKeyFilePath = "/etc/luks/"
 	resultFilePath          = "/etc/.ignition-result.json"
+	checksumFilePath        = "/etc/.ignition-result.sha256"
 )
 
 func DiskByLabelDir() string { return diskByLabelDir }
@@ -99,6 +108,7 @@ func SetfilesCmd() string  { return setfilesCmd }
 func WipefsCmd() string    { return wipefsCmd }
 func SystemctlCmd() string { return systemctlCmd }
 
+func FsckCmd() string      { return fsckCmd }
 func BtrfsMkfsCmd() string { return btrfsMkfsCmd }
 func Ext4MkfsCmd() string  { return ext4MkfsCmd }
 func SwapMkfsCmd() string  { return swapMkfsCmd }
@@ -112,10 +122,14 @@ func CioIgnoreCmd() string { return cioIgnoreCmd }
 func ClevisCmd() string     { return clevisCmd }
 func CryptsetupCmd() string { return cryptsetupCmd }
 
+func EfibootmgrCmd() string { return efibootmgrCmd }
+
 func KargsCmd() string { return kargsCmd }
 
 func LuksRealRootKeyFilePath() string { return luksRealRootKeyFilePath }
 func ResultFilePath() string          { return resultFilePath }
+func ChecksumFilePath() string        { return checksumFilePath }
+func ResultArtifactMaxSize() int      { return bakedStringToInt(resultArtifactMaxSize) }
 
 func SelinuxRelabel() bool  { return bakedStringToBool(selinuxRelabel) && !BlackboxTesting() }
 func BlackboxTesting() bool { return bakedStringToBool(blackboxTesting) }
@@ -142,3 +156,12 @@ func bakedStringToBool(s string) bool {
 		panic(fmt.Sprintf("value '%s' cannot be interpreted as a boolean", s))
 	}
 }
+
+func bakedStringToInt(s string) int {
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 {
+		// same as for booleans
+		panic(fmt.Sprintf("value '%s' cannot be interpreted as a size", s))
+	}
+	return i
+}
@@ -0,0 +1,166 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootmgr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/ignition/v2/internal/distro"
+	"github.com/coreos/ignition/v2/internal/log"
+)
+
+var (
+	ErrBadEfibootmgrOutput = errors.New("efibootmgr had unexpected output")
+
+	bootOrderRegex = regexp.MustCompile(`^BootOrder: ([0-9A-Fa-f,]*)$`)
+	entryRegex     = regexp.MustCompile(`^Boot([0-9A-Fa-f]{4})(\*?) (.*)$`)
+	hdRegex        = regexp.MustCompile(`HD\([0-9]+,GPT,([0-9A-Fa-f-]{36}),`)
+	// efibootmgr < 18 wraps the loader in File(); newer versions print it bare
+	fileRegex   = regexp.MustCompile(`/File\(([^)]*)\)`)
+	loaderRegex = regexp.MustCompile(`\)/(\\[^\s]*)`)
+)
+
+// Entry is an EFI boot entry, as reported by efibootmgr.
+type Entry struct {
+	// Num is the four hex digit boot number, e.g. "0001".
+	Num    string
+	Label  string
+	Active bool
+	// PartUUID is the GPT partition GUID the entry loads from, or empty
+	// if the entry isn't on a GPT partition.
+	PartUUID string
+	Loader   string
+}
+
+// State is the set of EFI boot entries and the boot order.
+type State struct {
+	BootOrder []string
+	Entries   []Entry
+}
+
+// GetEntry returns the entry with the given boot number.
+func (s State) GetEntry(num string) (Entry, bool) {
+	for _, e := range s.Entries {
+		if strings.EqualFold(e.Num, num) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Read returns the current EFI boot entries and boot order.
+func Read(logger *log.Logger) (State, error) {
+	output, err := run(logger, "reading EFI boot entries", "--verbose")
+	if err != nil {
+		return State{}, err
+	}
+	return parse(output)
+}
+
+// CreateEntry creates a boot entry loading loader from partition number part
+// of disk, without changing the boot order. It returns the boot number of
+// the new entry.
+func CreateEntry(logger *log.Logger, disk string, part int, label, loader string) (string, error) {
+	before, err := Read(logger)
+	if err != nil {
+		return "", err
+	}
+	output, err := run(logger, fmt.Sprintf("creating EFI boot entry %q", label),
+		"--create-only", "--disk", disk, "--part", strconv.Itoa(part), "--label", label, "--loader", loader)
+	if err != nil {
+		return "", err
+	}
+	after, err := parse(output)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range after.Entries {
+		if _, existed := before.GetEntry(e.Num); !existed && e.Label == label {
+			return e.Num, nil
+		}
+	}
+	return "", fmt.Errorf("couldn't find newly created EFI boot entry %q: %w", label, ErrBadEfibootmgrOutput)
+}
+
+// SetBootOrder replaces the boot order with order.
+func SetBootOrder(logger *log.Logger, order []string) error {
+	joined := strings.Join(order, ",")
+	_, err := run(logger, fmt.Sprintf("setting EFI boot order to %s", joined), "--bootorder", joined)
+	return err
+}
+
+func run(logger *log.Logger, desc string, args ...string) (string, error) {
+	var output string
+	err := logger.LogOp(func() error {
+		cmd := exec.Command(distro.EfibootmgrCmd(), args...)
+		logger.Debug("executing: %s", log.QuotedCmd(cmd))
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%v: Stderr: %q", err, stderr.Bytes())
+		}
+		output = stdout.String()
+		return nil
+	}, "%s", desc)
+	return output, err
+}
+
+// parse parses the output of efibootmgr --verbose.
+func parse(output string) (State, error) {
+	state := State{}
+	for _, line := range strings.Split(output, "\n") {
+		if matches := bootOrderRegex.FindStringSubmatch(line); matches != nil {
+			if matches[1] != "" {
+				state.BootOrder = strings.Split(matches[1], ",")
+			}
+			continue
+		}
+		matches := entryRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		entry := Entry{
+			Num:    strings.ToUpper(matches[1]),
+			Active: matches[2] == "*",
+		}
+		// the label and the device path are separated by a tab
+		label, devicePath, _ := strings.Cut(matches[3], "\t")
+		entry.Label = strings.TrimSpace(label)
+		if m := hdRegex.FindStringSubmatch(devicePath); m != nil {
+			entry.PartUUID = strings.ToLower(m[1])
+		}
+		if m := fileRegex.FindStringSubmatch(devicePath); m != nil {
+			entry.Loader = m[1]
+		} else if m := loaderRegex.FindStringSubmatch(devicePath); m != nil {
+			entry.Loader = m[1]
+		}
+		state.Entries = append(state.Entries, entry)
+	}
+	for i, num := range state.BootOrder {
+		if len(num) != 4 {
+			return State{}, ErrBadEfibootmgrOutput
+		}
+		state.BootOrder[i] = strings.ToUpper(num)
+	}
+	return state, nil
+}
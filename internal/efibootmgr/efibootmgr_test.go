@@ -0,0 +1,87 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in  string
+		out State
+		err error
+	}{
+		// efibootmgr 17
+		{
+			in: "BootCurrent: 0001\n" +
+				"Timeout: 0 seconds\n" +
+				"BootOrder: 0001,0000\n" +
+				"Boot0000* UiApp\tFvVol(7cb8bdc9-f8eb-4f34-aaea-3ee4af6516a1)/FvFile(462caa21-7614-4503-836e-8ab6f4662331)\n" +
+				"Boot0001* Fedora\tHD(2,GPT,5DFBF5F4-2848-4BAC-AA5E-0D9A20B745A6,0x1000,0x3f800)/File(\\EFI\\fedora\\shimx64.efi)\n",
+			out: State{
+				BootOrder: []string{"0001", "0000"},
+				Entries: []Entry{
+					{
+						Num:    "0000",
+						Label:  "UiApp",
+						Active: true,
+					},
+					{
+						Num:      "0001",
+						Label:    "Fedora",
+						Active:   true,
+						PartUUID: "5dfbf5f4-2848-4bac-aa5e-0d9a20b745a6",
+						Loader:   "\\EFI\\fedora\\shimx64.efi",
+					},
+				},
+			},
+		},
+		// efibootmgr 18
+		{
+			in: "BootCurrent: 0001\n" +
+				"BootOrder: 0001\n" +
+				"Boot0001  Fedora mirror\tHD(2,GPT,05ae8178-224e-4744-862a-4f4b042662d0,0x1000,0x3f800)/\\EFI\\fedora\\shimx64.efi\n",
+			out: State{
+				BootOrder: []string{"0001"},
+				Entries: []Entry{
+					{
+						Num:      "0001",
+						Label:    "Fedora mirror",
+						PartUUID: "05ae8178-224e-4744-862a-4f4b042662d0",
+						Loader:   "\\EFI\\fedora\\shimx64.efi",
+					},
+				},
+			},
+		},
+		// no boot order
+		{
+			in:  "BootCurrent: 0001\nBootOrder: \n",
+			out: State{},
+		},
+		{
+			in:  "BootOrder: 001,0002\n",
+			err: ErrBadEfibootmgrOutput,
+		},
+	}
+
+	for i, test := range tests {
+		out, err := parse(test.in)
+		assert.Equal(t, test.err, err, "#%d: bad error", i)
+		assert.Equal(t, test.out, out, "#%d: bad state", i)
+	}
+}
@@ -82,6 +82,15 @@ func (f *ConfigFetcher) RenderConfig(cfg types.Config) (types.Config, error) {
 			return types.Config{}, err
 		}
 
+		// check the rendered config, so the restrictions also apply to
+		// the configs it merges or replaces itself with
+		r := cfg.Ignition.Config.Restrictions.Check(newCfg)
+		f.Logger.LogReport(r)
+		if r.IsFatal() {
+			f.Logger.Crit("merged config violates the restrictions of the config merging it")
+			return types.Config{}, errors.ErrInvalid
+		}
+
 		mergedCfg = latest.Merge(mergedCfg, newCfg)
 	}
 	return mergedCfg, nil
@@ -97,6 +106,7 @@ func (f *ConfigFetcher) fetchReferencedConfig(cfgRef types.Resource) (types.Conf
 	}
 	u, err := url.Parse(*cfgRef.Source)
 	if err != nil {
+		f.Logger.Crit("Unable to parse config URL: %v", err)
 		return types.Config{}, err
 	}
 	var headers http.Header
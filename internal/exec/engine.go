@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -30,6 +31,7 @@ import (
 	executil "github.com/coreos/ignition/v2/internal/exec/util"
 	"github.com/coreos/ignition/v2/internal/log"
 	"github.com/coreos/ignition/v2/internal/platform"
+	"github.com/coreos/ignition/v2/internal/policy"
 	"github.com/coreos/ignition/v2/internal/providers/cmdline"
 	"github.com/coreos/ignition/v2/internal/providers/system"
 	"github.com/coreos/ignition/v2/internal/resource"
@@ -72,6 +74,9 @@ func (e Engine) Run(stageName string) error {
 		fmt.Fprintf(os.Stderr, "engine incorrectly configured\n")
 		return errors.ErrEngineConfiguration
 	}
+	if e.State.ProvisioningStart.IsZero() {
+		e.State.ProvisioningStart = time.Now()
+	}
 	baseConfig := emptyConfig
 
 	systemBaseConfig, r, err := system.FetchBaseConfig(e.Logger, e.PlatformConfig.Name())
@@ -125,6 +130,9 @@ func (e Engine) Run(stageName string) error {
 	defer e.Logger.PopPrefix()
 
 	fullConfig := latest.Merge(baseConfig, latest.Merge(systemBaseConfig, cfg))
+	if err := policy.Check(e.Logger, fullConfig); err != nil {
+		return err
+	}
 	err = stages.Get(stageName).Create(e.Logger, e.Root, *e.Fetcher, e.State).Run(fullConfig)
 	if err == resource.ErrNeedNet && stageName == "fetch-offline" {
 		err = e.signalNeedNet()
@@ -332,7 +340,7 @@ func (e *Engine) fetchProviderConfig() (types.Config, error) {
 }
 
 func (e *Engine) signalNeedNet() error {
-	if err := executil.MkdirForFile(e.NeedNet); err != nil {
+	if err := os.MkdirAll(filepath.Dir(e.NeedNet), executil.DefaultDirectoryPermissions); err != nil {
 		return err
 	}
 	if f, err := os.Create(e.NeedNet); err != nil {
@@ -72,7 +72,8 @@ func isNoOp(config types.Config) bool {
 	return len(config.Storage.Disks) == 0 &&
 		len(config.Storage.Raid) == 0 &&
 		len(config.Storage.Filesystems) == 0 &&
-		len(config.Storage.Luks) == 0
+		len(config.Storage.Luks) == 0 &&
+		len(config.Efi.BootEntries) == 0
 }
 
 func (s stage) Apply(config types.Config, ignoreUnsupported bool) error {
@@ -107,6 +108,14 @@ func (s stage) Run(config types.Config) error {
 		return fmt.Errorf("failed to create filesystems: %v", err)
 	}
 
+	if err := s.mirrorEsps(config); err != nil {
+		return fmt.Errorf("failed to mirror ESPs: %v", err)
+	}
+
+	if err := s.createBootEntries(config); err != nil {
+		return fmt.Errorf("failed to create EFI boot entries: %v", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,287 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disks
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
+	"github.com/coreos/ignition/v2/internal/efibootmgr"
+	"github.com/coreos/ignition/v2/internal/exec/util"
+	iutil "github.com/coreos/ignition/v2/internal/util"
+
+	"golang.org/x/sys/unix"
+)
+
+// mirrorEsps copies the contents of the EFI System Partitions given as
+// espMirror sources onto the filesystems mirroring them, and duplicates
+// their EFI boot entries if requested.
+func (s stage) mirrorEsps(config types.Config) error {
+	mirrors := []types.Filesystem{}
+	devs := []string{}
+	for _, fs := range config.Storage.Filesystems {
+		if fs.EspMirror.IsPresent() {
+			mirrors = append(mirrors, fs)
+			devs = append(devs, *fs.EspMirror.Source)
+		}
+	}
+	if len(mirrors) == 0 {
+		return nil
+	}
+
+	s.Logger.PushPrefix("mirrorEsps")
+	defer s.Logger.PopPrefix()
+
+	if err := s.waitOnDevicesAndCreateAliases(devs, "ESP mirror sources"); err != nil {
+		return err
+	}
+
+	for _, fs := range mirrors {
+		src := *fs.EspMirror.Source
+		if s.SkipOptional(config, "mirroring ESP %q to %q", src, fs.Device) {
+			continue
+		}
+		if err := s.Logger.LogOp(func() error {
+			return copyEsp(util.DeviceAlias(src), util.DeviceAlias(fs.Device))
+		}, "copying ESP %q to %q", src, fs.Device); err != nil {
+			return err
+		}
+		if cutil.IsTrue(fs.EspMirror.BootEntries) {
+			if err := s.mirrorBootEntries(util.DeviceAlias(src), util.DeviceAlias(fs.Device)); err != nil {
+				return fmt.Errorf("duplicating EFI boot entries of %q for %q: %v", src, fs.Device, err)
+			}
+		}
+	}
+	return nil
+}
+
+// copyEsp mounts the vfat filesystems on src and dst and copies all files
+// and directories from src to dst.
+func copyEsp(src, dst string) error {
+	srcMnt, err := os.MkdirTemp("", "ignition-esp-src")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	defer os.Remove(srcMnt)
+	dstMnt, err := os.MkdirTemp("", "ignition-esp-dst")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	defer os.Remove(dstMnt)
+
+	if err := unix.Mount(src, srcMnt, "vfat", unix.MS_RDONLY, ""); err != nil {
+		return fmt.Errorf("failed to mount %q: %v", src, err)
+	}
+	defer func() {
+		_ = iutil.UmountPath(srcMnt)
+	}()
+	if err := unix.Mount(dst, dstMnt, "vfat", 0, ""); err != nil {
+		return fmt.Errorf("failed to mount %q: %v", dst, err)
+	}
+	if err := copyTree(srcMnt, dstMnt); err != nil {
+		_ = iutil.UmountPath(dstMnt)
+		return err
+	}
+	// unmount explicitly so write-back errors aren't lost
+	return iutil.UmountPath(dstMnt)
+}
+
+// copyTree recursively copies the directories and regular files in src to
+// dst. vfat has nothing else worth copying.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type().IsRegular():
+			return copyFile(path, target)
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := iutil.CopyFile(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copying %q: %v", src, err)
+	}
+	return out.Close()
+}
+
+// mirrorBootEntries creates a copy of every EFI boot entry loading from the
+// src partition which loads from the dst partition instead, and places each
+// copy directly after its original in the boot order.
+func (s stage) mirrorBootEntries(src, dst string) error {
+	srcDisk, srcNum, err := resolvePartition(src)
+	if err != nil {
+		return err
+	}
+	dstDisk, dstNum, err := resolvePartition(dst)
+	if err != nil {
+		return err
+	}
+	srcInfo, err := s.getPartitionMap(srcDisk)
+	if err != nil {
+		return err
+	}
+	srcPart, ok := srcInfo.GetPartition(srcNum)
+	if !ok {
+		return fmt.Errorf("couldn't find partition %d on %q", srcNum, srcDisk)
+	}
+
+	state, err := efibootmgr.Read(s.Logger)
+	if err != nil {
+		return err
+	}
+	mirrored := map[string]string{}
+	for _, entry := range state.Entries {
+		if entry.PartUUID == "" || !strings.EqualFold(entry.PartUUID, srcPart.GUID) || entry.Loader == "" {
+			continue
+		}
+		num, err := efibootmgr.CreateEntry(s.Logger, dstDisk, dstNum, entry.Label+" (mirror)", entry.Loader)
+		if err != nil {
+			return err
+		}
+		mirrored[entry.Num] = num
+	}
+	if len(mirrored) == 0 {
+		s.Logger.Warning("no EFI boot entries found for %q; not creating any for %q", src, dst)
+		return nil
+	}
+
+	order := []string{}
+	for _, num := range state.BootOrder {
+		order = append(order, num)
+		if mirror, ok := mirrored[num]; ok {
+			order = append(order, mirror)
+		}
+	}
+	return efibootmgr.SetBootOrder(s.Logger, order)
+}
+
+// createBootEntries creates the EFI boot entries described in
+// config.Efi.BootEntries, reusing matching existing entries, and places them
+// at the start of the boot order in the order they're listed.
+func (s stage) createBootEntries(config types.Config) error {
+	entries := config.Efi.BootEntries
+	if len(entries) == 0 {
+		return nil
+	}
+	if s.SkipOptional(config, "creating EFI boot entries") {
+		return nil
+	}
+
+	s.Logger.PushPrefix("createBootEntries")
+	defer s.Logger.PopPrefix()
+
+	devs := []string{}
+	for _, entry := range entries {
+		devs = append(devs, *entry.Device)
+	}
+	if err := s.waitOnDevicesAndCreateAliases(devs, "EFI boot entries"); err != nil {
+		return err
+	}
+
+	state, err := efibootmgr.Read(s.Logger)
+	if err != nil {
+		return err
+	}
+	nums := []string{}
+	for _, entry := range entries {
+		disk, partNum, err := resolvePartition(util.DeviceAlias(*entry.Device))
+		if err != nil {
+			return err
+		}
+		diskInfo, err := s.getPartitionMap(disk)
+		if err != nil {
+			return err
+		}
+		part, ok := diskInfo.GetPartition(partNum)
+		if !ok {
+			return fmt.Errorf("couldn't find partition %d on %q", partNum, disk)
+		}
+		loader := strings.ReplaceAll(*entry.Loader, "/", `\`)
+
+		num := ""
+		for _, existing := range state.Entries {
+			if existing.Label == entry.Label && strings.EqualFold(existing.PartUUID, part.GUID) &&
+				strings.EqualFold(existing.Loader, loader) {
+				s.Logger.Info("found existing EFI boot entry %s for %q", existing.Num, entry.Label)
+				num = existing.Num
+				break
+			}
+		}
+		if num == "" {
+			if num, err = efibootmgr.CreateEntry(s.Logger, disk, partNum, entry.Label, loader); err != nil {
+				return err
+			}
+		}
+		nums = append(nums, num)
+	}
+
+	order := append([]string{}, nums...)
+	for _, num := range state.BootOrder {
+		if !iutil.StrSliceContains(nums, num) {
+			order = append(order, num)
+		}
+	}
+	return efibootmgr.SetBootOrder(s.Logger, order)
+}
+
+// resolvePartition returns the disk containing the partition dev and the
+// partition's number.
+func resolvePartition(dev string) (string, int, error) {
+	devPath, err := filepath.EvalSymlinks(dev)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to resolve %q: %v", dev, err)
+	}
+	sysPath, err := filepath.EvalSymlinks(filepath.Join("/sys/class/block", filepath.Base(devPath)))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to resolve sysfs entry of %q: %v", devPath, err)
+	}
+	contents, err := os.ReadFile(filepath.Join(sysPath, "partition"))
+	if err != nil {
+		return "", 0, fmt.Errorf("%q is not a partition: %v", devPath, err)
+	}
+	num, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return "", 0, fmt.Errorf("parsing partition number of %q: %v", devPath, err)
+	}
+	return filepath.Join("/dev", filepath.Base(filepath.Dir(sysPath))), num, nil
+}
@@ -85,9 +85,34 @@ func partitionMatchesResize(existing util.PartitionInfo, spec sgdisk.Partition)
 	return cutil.IsTrue(spec.Resize) && partitionMatchesCommon(existing, spec) == nil
 }
 
+// partitionMatchesRetype returns if the existing partition should have its type GUID and label
+// updated in place by evaluating if `retype` field is true and partition matches in all respects
+// except type GUID and label.
+func partitionMatchesRetype(existing util.PartitionInfo, spec sgdisk.Partition) bool {
+	if !cutil.IsTrue(spec.Retype) || partitionMatchesLocation(existing, spec) != nil {
+		return false
+	}
+	return spec.SizeInSectors == nil || *spec.SizeInSectors == existing.SizeInSectors
+}
+
 // partitionMatchesCommon handles the common tests (excluding the partition size) to determine
 // if the existing partition matches the spec given.
 func partitionMatchesCommon(existing util.PartitionInfo, spec sgdisk.Partition) error {
+	if err := partitionMatchesLocation(existing, spec); err != nil {
+		return err
+	}
+	if cutil.NotEmpty(spec.TypeGUID) && !strings.EqualFold(*spec.TypeGUID, existing.TypeGUID) {
+		return fmt.Errorf("type GUID did not match (specified %q, got %q)", *spec.TypeGUID, existing.TypeGUID)
+	}
+	if spec.Label != nil && *spec.Label != existing.Label {
+		return fmt.Errorf("label did not match (specified %q, got %q)", *spec.Label, existing.Label)
+	}
+	return nil
+}
+
+// partitionMatchesLocation handles the tests (excluding the partition size, type GUID, and label)
+// that identify the existing partition as the one given by the spec.
+func partitionMatchesLocation(existing util.PartitionInfo, spec sgdisk.Partition) error {
 	if spec.Number != existing.Number {
 		return fmt.Errorf("partition numbers did not match (specified %d, got %d). This should not happen, please file a bug.", spec.Number, existing.Number)
 	}
@@ -97,12 +122,6 @@ func partitionMatchesCommon(existing util.PartitionInfo, spec sgdisk.Partition)
 	if cutil.NotEmpty(spec.GUID) && !strings.EqualFold(*spec.GUID, existing.GUID) {
 		return fmt.Errorf("GUID did not match (specified %q, got %q)", *spec.GUID, existing.GUID)
 	}
-	if cutil.NotEmpty(spec.TypeGUID) && !strings.EqualFold(*spec.TypeGUID, existing.TypeGUID) {
-		return fmt.Errorf("type GUID did not match (specified %q, got %q)", *spec.TypeGUID, existing.TypeGUID)
-	}
-	if spec.Label != nil && *spec.Label != existing.Label {
-		return fmt.Errorf("label did not match (specified %q, got %q)", *spec.Label, existing.Label)
-	}
 	return nil
 }
 
@@ -383,6 +402,9 @@ func (s stage) partitionDisk(dev types.Disk, devAlias string) error {
 				part.Label = &info.Label
 				part.StartSector = &info.StartSector
 				op.CreatePartition(part)
+			} else if partitionMatchesRetype(info, part) {
+				s.Logger.Info("updating type GUID and label of partition %d in place", part.Number)
+				op.UpdatePartition(part)
 			} else {
 				return fmt.Errorf("Partition %d didn't match: %v", part.Number, matchErr)
 			}
@@ -61,6 +61,8 @@ func (creator) Name() string {
 type stage struct {
 	util.Util
 	toRelabel map[string]struct{}
+	// regular files written by this stage, for the checksum file
+	written map[string]struct{}
 }
 
 func (stage) Name() string {
@@ -119,6 +121,11 @@ func (s stage) runImpl(config types.Config, isApply bool, applyIgnoreUnsupported
 			return fmt.Errorf("creating result file: %v", err)
 		}
 
+		// !isApply: only written alongside the result file
+		if err := s.createChecksumFile(); err != nil {
+			return fmt.Errorf("creating checksum file: %v", err)
+		}
+
 		// !isApply: SELinux is handled differently in container flows
 		if err := s.relabelFiles(); err != nil {
 			return fmt.Errorf("failed to handle relabeling: %v", err)
@@ -15,12 +15,17 @@
 package files
 
 import (
+	"fmt"
+	"os"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
+	cutil "github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
 	"github.com/coreos/ignition/v2/internal/exec/util"
+	"github.com/coreos/ignition/v2/internal/log"
 )
 
 func TestEntrySort(t *testing.T) {
@@ -211,3 +216,333 @@ func TestEntrySort(t *testing.T) {
 		}
 	}
 }
+
+func TestChecksumLine(t *testing.T) {
+	sum := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	tests := []struct {
+		path string
+		out  string
+	}{
+		{
+			path: "/etc/hostname",
+			out:  sum + "  /etc/hostname\n",
+		},
+		{
+			path: "/etc/a file",
+			out:  sum + "  /etc/a file\n",
+		},
+		{
+			path: "/etc/back\\slash",
+			out:  "\\" + sum + "  /etc/back\\\\slash\n",
+		},
+		{
+			path: "/etc/new\nline",
+			out:  "\\" + sum + "  /etc/new\\nline\n",
+		},
+	}
+
+	for i, test := range tests {
+		if out := checksumLine(sum, test.path); out != test.out {
+			t.Errorf("#%d: bad line: want %q, got %q", i, test.out, out)
+		}
+	}
+}
+
+func TestChecksumList(t *testing.T) {
+	sum := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	lines := []string{sum + "  /a\n", sum + "  /b\n", sum + "  /c\n"}
+	tests := []struct {
+		max     int
+		out     string
+		omitted int
+	}{
+		{
+			max: 0,
+			out: lines[0] + lines[1] + lines[2],
+		},
+		{
+			max: 207,
+			out: lines[0] + lines[1] + lines[2],
+		},
+		{
+			max:     206,
+			out:     lines[0] + lines[1] + truncationMarker(1),
+			omitted: 1,
+		},
+		{
+			max:     150,
+			out:     lines[0] + truncationMarker(2),
+			omitted: 2,
+		},
+		{
+			max:     1,
+			out:     truncationMarker(3),
+			omitted: 3,
+		},
+	}
+
+	for i, test := range tests {
+		out, omitted := checksumList(lines, test.max)
+		if string(out) != test.out || omitted != test.omitted {
+			t.Errorf("#%d: bad list: want %q (%d omitted), got %q (%d omitted)", i, test.out, test.omitted, out, omitted)
+		}
+	}
+}
+
+func TestMarshalResult(t *testing.T) {
+	skipped := make([]string, 100)
+	for i := range skipped {
+		skipped[i] = fmt.Sprintf("writing file %q", strings.Repeat("x", 50))
+	}
+	previous := map[string]interface{}{"provisioningDate": strings.Repeat("y", 4000)}
+
+	tests := []struct {
+		in       resultReport
+		max      int
+		previous bool
+		omitted  bool
+		fits     bool
+	}{
+		// no limit
+		{resultReport{PreviousReport: previous, SkippedOperations: skipped}, 0, true, false, true},
+		// fits
+		{resultReport{PreviousReport: previous, SkippedOperations: skipped}, 100000, true, false, true},
+		// previous report dropped
+		{resultReport{PreviousReport: previous, SkippedOperations: skipped}, 8000, false, false, true},
+		// current report alone exceeds the limit
+		{resultReport{SkippedOperations: skipped}, 2000, false, true, true},
+		{resultReport{PreviousReport: previous, SkippedOperations: skipped}, 2000, false, true, true},
+		// can't fit
+		{resultReport{SkippedOperations: skipped}, 10, false, true, false},
+	}
+
+	for i, test := range tests {
+		result := test.in
+		data, err := marshalResult(&result, test.max)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if fits := test.max == 0 || len(data) <= test.max; fits != test.fits {
+			t.Errorf("#%d: expected fitting %v, got %d bytes", i, test.fits, len(data))
+		}
+		if kept := result.PreviousReport != nil; kept != test.previous || result.PreviousReportTruncated != (test.in.PreviousReport != nil && !kept) {
+			t.Errorf("#%d: expected previous report kept %v, got %v (truncated %v)", i, test.previous, kept, result.PreviousReportTruncated)
+		}
+		if omitted := result.SkippedOperationsOmitted > 0; omitted != test.omitted {
+			t.Errorf("#%d: expected skipped operations omitted %v, got %d omitted", i, test.omitted, result.SkippedOperationsOmitted)
+		}
+		if len(result.SkippedOperations)+result.SkippedOperationsOmitted != len(test.in.SkippedOperations) {
+			t.Errorf("#%d: kept %d and omitted %d of %d skipped operations", i, len(result.SkippedOperations), result.SkippedOperationsOmitted, len(test.in.SkippedOperations))
+		}
+		if !strings.HasSuffix(string(data), "\n") {
+			t.Errorf("#%d: missing trailing newline", i)
+		}
+	}
+}
+
+func TestDirEntryCreate(t *testing.T) {
+	logger := log.New(true)
+	defer logger.Close()
+
+	tests := []struct {
+		existing   func(fs *util.MemFS) error
+		overwrite  bool
+		onConflict string
+		err        bool
+		kept       bool
+		child      bool
+	}{
+		// nothing there
+		{
+			existing: func(*util.MemFS) error { return nil },
+		},
+		// directory already there
+		{
+			existing: func(fs *util.MemFS) error { return fs.MkdirAll("/a/dir", 0700) },
+		},
+		{
+			existing: func(fs *util.MemFS) error { return fs.WriteFile("/a/dir", nil, 0644) },
+			err:      true,
+		},
+		{
+			existing:  func(fs *util.MemFS) error { return fs.WriteFile("/a/dir", nil, 0644) },
+			overwrite: true,
+		},
+		{
+			existing:   func(fs *util.MemFS) error { return fs.WriteFile("/a/dir", nil, 0644) },
+			onConflict: "fail",
+			err:        true,
+		},
+		{
+			existing:   func(fs *util.MemFS) error { return fs.WriteFile("/a/dir", nil, 0644) },
+			onConflict: "replace",
+		},
+		{
+			existing:   func(fs *util.MemFS) error { return fs.WriteFile("/a/dir", nil, 0644) },
+			onConflict: "keep",
+			kept:       true,
+		},
+		// replace keeps the contents of an existing directory
+		{
+			existing: func(fs *util.MemFS) error {
+				if err := fs.MkdirAll("/a/dir", 0700); err != nil {
+					return err
+				}
+				return fs.WriteFile("/a/dir/file", nil, 0644)
+			},
+			onConflict: "replace",
+			child:      true,
+		},
+	}
+
+	for i, test := range tests {
+		fs := util.NewMemFS()
+		if err := fs.MkdirAll("/a", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := test.existing(fs); err != nil {
+			t.Fatal(err)
+		}
+		entry := dirEntry{
+			Node: types.Node{
+				Path:      "/a/dir",
+				Overwrite: cutil.BoolToPtr(test.overwrite),
+				User:      types.NodeUser{ID: cutil.IntToPtr(1000)},
+			},
+			DirectoryEmbedded1: types.DirectoryEmbedded1{Mode: cutil.IntToPtr(0750)},
+		}
+		if test.onConflict != "" {
+			entry.OnConflict = cutil.StrToPtr(test.onConflict)
+		}
+		s := stage{Util: util.Util{FileSystem: fs}}
+		err := s.removePathOnOverwrite(entry)
+		if err == nil {
+			err = entry.create(&logger, s.Util)
+		}
+		if (err != nil) != test.err {
+			t.Errorf("#%d: expected error %v, got %v", i, test.err, err)
+		}
+		if test.err {
+			continue
+		}
+		n, _ := fs.Get("/a/dir")
+		if test.kept {
+			if n == nil || !n.Mode.IsRegular() {
+				t.Errorf("#%d: existing file not kept: %+v", i, n)
+			}
+			continue
+		}
+		if n == nil || n.Mode != os.ModeDir|0750 || n.Uid != 1000 {
+			t.Errorf("#%d: bad directory %+v", i, n)
+		}
+		if _, ok := fs.Get("/a/dir/file"); test.child && !ok {
+			t.Errorf("#%d: directory contents not kept", i)
+		}
+	}
+}
+
+func TestLinkEntryCreate(t *testing.T) {
+	logger := log.New(true)
+	defer logger.Close()
+
+	tests := []struct {
+		existing   func(fs *util.MemFS) error
+		hard       bool
+		onConflict string
+		err        bool
+		kept       bool
+	}{
+		// nothing there
+		{
+			existing: func(*util.MemFS) error { return nil },
+		},
+		{
+			existing: func(*util.MemFS) error { return nil },
+			hard:     true,
+		},
+		// same link already there
+		{
+			existing: func(fs *util.MemFS) error { return fs.Symlink("/target", "/link") },
+		},
+		{
+			existing: func(fs *util.MemFS) error { return fs.Link("/target", "/link") },
+			hard:     true,
+		},
+		// different link there
+		{
+			existing: func(fs *util.MemFS) error { return fs.Symlink("/other", "/link") },
+			err:      true,
+		},
+		{
+			existing: func(fs *util.MemFS) error { return fs.WriteFile("/link", nil, 0644) },
+			hard:     true,
+			err:      true,
+		},
+		// node of another kind there
+		{
+			existing:   func(fs *util.MemFS) error { return fs.MkdirAll("/link/dir", 0755) },
+			onConflict: "replace",
+		},
+		{
+			existing:   func(fs *util.MemFS) error { return fs.MkdirAll("/link", 0755) },
+			onConflict: "keep",
+			kept:       true,
+		},
+		{
+			existing:   func(fs *util.MemFS) error { return fs.WriteFile("/link", nil, 0644) },
+			hard:       true,
+			onConflict: "replace",
+		},
+		{
+			existing:   func(fs *util.MemFS) error { return fs.WriteFile("/link", nil, 0644) },
+			hard:       true,
+			onConflict: "keep",
+			kept:       true,
+		},
+	}
+
+	for i, test := range tests {
+		fs := util.NewMemFS()
+		if err := fs.WriteFile("/target", nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := test.existing(fs); err != nil {
+			t.Fatal(err)
+		}
+		entry := linkEntry{
+			Node: types.Node{Path: "/link"},
+			LinkEmbedded1: types.LinkEmbedded1{
+				Target: cutil.StrToPtr("/target"),
+				Hard:   cutil.BoolToPtr(test.hard),
+			},
+		}
+		if test.onConflict != "" {
+			entry.OnConflict = cutil.StrToPtr(test.onConflict)
+		}
+		s := stage{Util: util.Util{FileSystem: fs}}
+		err := s.removePathOnOverwrite(entry)
+		if err == nil {
+			err = entry.create(&logger, s.Util)
+		}
+		if (err != nil) != test.err {
+			t.Errorf("#%d: expected error %v, got %v", i, test.err, err)
+		}
+		if test.err {
+			continue
+		}
+		link, _ := fs.Lstat("/link")
+		target, _ := fs.Lstat("/target")
+		if test.kept {
+			if link == nil || link.Mode()&os.ModeSymlink != 0 || util.SameFile(link, target) {
+				t.Errorf("#%d: existing node not kept", i)
+			}
+			continue
+		}
+		if test.hard && !util.SameFile(link, target) {
+			t.Errorf("#%d: link isn't a hard link to the target", i)
+		} else if target, _ := fs.Readlink("/link"); !test.hard && target != "/target" {
+			t.Errorf("#%d: bad symlink target %q", i, target)
+		}
+	}
+}
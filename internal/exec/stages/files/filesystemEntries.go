@@ -15,8 +15,12 @@
 package files
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -185,15 +189,11 @@ func (s *stage) createResultFile() error {
 		return fmt.Errorf("reading boot ID: %w", err)
 	}
 
-	result := struct {
-		ProvisioningBootID string      `json:"provisioningBootID"`
-		ProvisioningDate   string      `json:"provisioningDate"`
-		UserConfigProvided bool        `json:"userConfigProvided"`
-		PreviousReport     interface{} `json:"previousReport,omitempty"`
-	}{
+	result := resultReport{
 		ProvisioningBootID: strings.TrimSpace(string(bootIDBytes)),
 		ProvisioningDate:   time.Now().Format(time.RFC3339),
 		PreviousReport:     prevReport,
+		SkippedOperations:  s.State.SkippedOperations,
 	}
 	for _, config := range s.State.FetchedConfigs {
 		if config.Kind == "user" {
@@ -202,11 +202,20 @@ func (s *stage) createResultFile() error {
 		}
 	}
 
-	data, err := json.MarshalIndent(result, "", "  ")
+	max := distro.ResultArtifactMaxSize()
+	data, err := marshalResult(&result, max)
 	if err != nil {
 		return fmt.Errorf("marshaling result file: %w", err)
 	}
-	data = append(data, '\n')
+	if result.PreviousReportTruncated {
+		s.Logger.Warning("result file size limit of %d bytes reached; dropping previous reports", max)
+	}
+	if result.SkippedOperationsOmitted > 0 {
+		s.Logger.Warning("result file size limit of %d bytes reached; omitting %d skipped operations", max, result.SkippedOperationsOmitted)
+	}
+	if max > 0 && len(data) > max {
+		return fmt.Errorf("result file is %d bytes even after truncation, exceeding the limit of %d bytes", len(data), max)
+	}
 
 	path, err := s.JoinPath(distro.ResultFilePath())
 	if err != nil {
@@ -236,6 +245,180 @@ func (s *stage) createResultFile() error {
 	return nil
 }
 
+// resultReport is the contents of the result file.
+type resultReport struct {
+	ProvisioningBootID string      `json:"provisioningBootID"`
+	ProvisioningDate   string      `json:"provisioningDate"`
+	UserConfigProvided bool        `json:"userConfigProvided"`
+	PreviousReport     interface{} `json:"previousReport,omitempty"`
+	// set if PreviousReport was dropped to stay within the size limit
+	PreviousReportTruncated bool `json:"previousReportTruncated,omitempty"`
+	// optional operations skipped because the provisioning budget ran
+	// out
+	SkippedOperations []string `json:"skippedOperations,omitempty"`
+	// the number of operations left out of SkippedOperations to stay
+	// within the size limit
+	SkippedOperationsOmitted int `json:"skippedOperationsOmitted,omitempty"`
+}
+
+// marshalResult marshals result with a trailing newline. If max is nonzero
+// and the result would exceed max bytes, it first drops the nested reports
+// of previous runs, which grow with every run, and then skipped operations
+// from the end of the list, recording what was dropped in result. The
+// returned data can still exceed max if the remaining fields don't fit.
+func marshalResult(result *resultReport, max int) ([]byte, error) {
+	marshal := func() ([]byte, error) {
+		data, err := json.MarshalIndent(result, "", "  ")
+		return append(data, '\n'), err
+	}
+	data, err := marshal()
+	if err != nil || max == 0 || len(data) <= max {
+		return data, err
+	}
+	if result.PreviousReport != nil {
+		result.PreviousReport = nil
+		result.PreviousReportTruncated = true
+		if data, err = marshal(); err != nil || len(data) <= max {
+			return data, err
+		}
+	}
+	for len(result.SkippedOperations) > 0 && len(data) > max {
+		result.SkippedOperations = result.SkippedOperations[:len(result.SkippedOperations)-1]
+		result.SkippedOperationsOmitted++
+		if data, err = marshal(); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// recordWritten adds path to the list of files to include in the checksum
+// file.
+func (s *stage) recordWritten(path string) {
+	if s.written == nil {
+		s.written = make(map[string]struct{})
+	}
+	s.written[path] = struct{}{}
+}
+
+// createChecksumFile writes the SHA-256 checksums of all regular files
+// written by the stage, including units, drop-ins, the preset file, SSH
+// authorized keys files, and the passwd and group databases edited for
+// users and groups, in the format of sha256sum, so they can be verified
+// later with sha256sum -c.
+func (s *stage) createChecksumFile() error {
+	if distro.ChecksumFilePath() == "" {
+		return nil
+	}
+
+	s.Logger.PushPrefix("createChecksumFile")
+	defer s.Logger.PopPrefix()
+
+	paths := make([]string, 0, len(s.written))
+	for path := range s.written {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	lines := []string{}
+	for _, path := range paths {
+		// devices written with device=true, files replaced since, or
+		// passwd databases the system doesn't have
+		st, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !st.Mode().IsRegular() {
+			continue
+		}
+		sum, err := sha256File(path)
+		if err != nil {
+			return fmt.Errorf("checksumming %q: %v", path, err)
+		}
+		lines = append(lines, checksumLine(sum, filepath.Join("/", strings.TrimPrefix(path, s.DestDir))))
+	}
+	data, omitted := checksumList(lines, distro.ResultArtifactMaxSize())
+	if omitted > 0 {
+		s.Logger.Warning("checksum file size limit of %d bytes reached; omitting %d files", distro.ResultArtifactMaxSize(), omitted)
+	}
+
+	path, err := s.JoinPath(distro.ChecksumFilePath())
+	if err != nil {
+		return fmt.Errorf("building checksum file path: %w", err)
+	}
+	contentsUri := dataurl.EncodeBytes(data)
+	entries := []filesystemEntry{
+		fileEntry{
+			types.Node{
+				Path:      path,
+				Overwrite: cutil.BoolToPtr(true),
+			},
+			types.FileEmbedded1{
+				Contents: types.Resource{
+					Source: &contentsUri,
+				},
+				Mode: cutil.IntToPtr(0600),
+			},
+		},
+	}
+	if err := s.createEntries(entries); err != nil {
+		return fmt.Errorf("adding checksum file: %v", err)
+	}
+	return nil
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checksumList concatenates lines, leaving out lines at the end to stay
+// within max bytes if max is nonzero. If any are left out, a line noting
+// how many is added, which sha256sum -c skips as improperly formatted.
+// It returns the number of lines left out.
+func checksumList(lines []string, max int) ([]byte, int) {
+	data := []byte(strings.Join(lines, ""))
+	if max == 0 || len(data) <= max {
+		return data, 0
+	}
+	data = data[:0]
+	for i, line := range lines {
+		marker := truncationMarker(len(lines) - i)
+		if len(data)+len(line)+len(marker) > max {
+			return append(data, marker...), len(lines) - i
+		}
+		data = append(data, line...)
+	}
+	// unreachable, since the lines didn't fit
+	return data, 0
+}
+
+func truncationMarker(omitted int) string {
+	return fmt.Sprintf("# truncated: %d more files not listed\n", omitted)
+}
+
+// checksumLine formats a line of sha256sum output. Like sha256sum, it
+// escapes backslashes and newlines in the path and marks such lines with a
+// leading backslash.
+func checksumLine(sum, path string) string {
+	if !strings.ContainsAny(path, "\\\n") {
+		return fmt.Sprintf("%s  %s\n", sum, path)
+	}
+	path = strings.ReplaceAll(path, "\\", "\\\\")
+	path = strings.ReplaceAll(path, "\n", "\\n")
+	return fmt.Sprintf("\\%s  %s\n", sum, path)
+}
+
 // createFilesystemsEntries creates the files described in config.Storage.{Files,Directories}.
 func (s *stage) createFilesystemsEntries(config types.Config) error {
 	s.Logger.PushPrefix("createFilesystemsFiles")
@@ -246,6 +429,10 @@ func (s *stage) createFilesystemsEntries(config types.Config) error {
 		return err
 	}
 
+	if err := s.checkEntriesWritable(entries); err != nil {
+		return err
+	}
+
 	if err := s.createEntries(entries); err != nil {
 		return fmt.Errorf("failed to create files: %v", err)
 	}
@@ -253,6 +440,22 @@ func (s *stage) createFilesystemsEntries(config types.Config) error {
 	return nil
 }
 
+// checkEntriesWritable fails if any entry would be created on a read-only
+// filesystem, before any entry is created. Writing to existing devices
+// doesn't modify the filesystem they're on, so those entries are skipped.
+func (s *stage) checkEntriesWritable(entries []filesystemEntry) error {
+	for _, e := range entries {
+		if f, ok := e.(fileEntry); ok && cutil.IsTrue(f.Device) {
+			continue
+		}
+		if err := s.CheckWritable(e.node().Path); err != nil {
+			s.Logger.Crit("%v", err)
+			return err
+		}
+	}
+	return nil
+}
+
 // filesystemEntry represent a thing that knows how to create itself.
 type filesystemEntry interface {
 	// create creates the entry if specified. It assumes that if overwrite=true then any existing
@@ -270,28 +473,27 @@ func (tmp fileEntry) node() types.Node {
 func (tmp fileEntry) create(l *log.Logger, u util.Util) error {
 	f := types.File(tmp)
 
+	if cutil.IsTrue(f.Device) {
+		return tmp.writeDevice(l, u)
+	}
+
 	empty := "" // golang--
 
-	st, err := os.Lstat(f.Path)
-	regular := (st == nil) || st.Mode().IsRegular()
+	st, err := u.CheckNode(f.Path, util.NodeFile, util.PolicyFor(f.Node))
+	var conflict util.NodeConflictError
 	switch {
-	case os.IsNotExist(err) && f.Contents.Source == nil:
+	case errors.As(err, &conflict) && conflict.Kept:
+		l.Warning("not creating file %q: %v", f.Path, err)
+		return nil
+	case errors.As(err, &conflict) && conflict.Found == util.NodeDevice:
+		return fmt.Errorf("error creating file %q: %v; set device to write to it", f.Path, err)
+	case err != nil:
+		return fmt.Errorf("error creating file %q: %v", f.Path, err)
+	case st == nil && f.Contents.Source == nil:
 		// set f.Contents so we create an empty file
 		f.Contents.Source = &empty
-	case os.IsNotExist(err):
-		break
-	case err != nil:
-		return err
-	// Cases where there is file there
-	case !regular:
-		return fmt.Errorf("error creating file %q: A non regular file exists there already and overwrite is false", f.Path)
-	case f.Contents.Source != nil:
+	case st != nil && f.Contents.Source != nil:
 		return fmt.Errorf("error creating file %q: A file exists there already and overwrite is false", f.Path)
-	case regular && f.Contents.Source == nil:
-		break
-	default:
-		// nolint:staticcheck
-		return fmt.Errorf("Ignition encountered an internal error processing %q and must die now. Please file a bug", f.Path)
 	}
 
 	fetchOps, err := u.PrepareFetches(l, f)
@@ -318,6 +520,43 @@ func (tmp fileEntry) create(l *log.Logger, u util.Util) error {
 	return nil
 }
 
+// writeDevice replaces the contents of the existing block or character
+// device at the path of the entry. The device node belongs to the running
+// system, so its permissions and ownership are left alone.
+func (tmp fileEntry) writeDevice(l *log.Logger, u util.Util) error {
+	f := types.File(tmp)
+
+	// follow symlinks, since devices are usually referenced through them
+	st, err := u.FS().Stat(f.Path)
+	switch {
+	case os.IsNotExist(err):
+		return fmt.Errorf("error writing device %q: no device exists there", f.Path)
+	case err != nil:
+		return err
+	case util.KindOf(st.Mode()) != util.NodeDevice:
+		return fmt.Errorf("error writing device %q: %v", f.Path, util.NodeConflictError{
+			Path:  f.Path,
+			Want:  util.NodeDevice,
+			Found: util.KindOf(st.Mode()),
+		})
+	}
+
+	fetchOps, err := u.PrepareFetches(l, f)
+	if err != nil {
+		return fmt.Errorf("failed to resolve file %q: %v", f.Path, err)
+	}
+	for _, op := range fetchOps {
+		if err := l.LogOp(
+			func() error {
+				return u.PerformFetch(op)
+			}, "writing device %q", f.Path,
+		); err != nil {
+			return fmt.Errorf("failed to write device %q: %v", op.Node.Path, err)
+		}
+	}
+	return nil
+}
+
 type dirEntry types.Directory
 
 func (tmp dirEntry) node() types.Node {
@@ -326,17 +565,19 @@ func (tmp dirEntry) node() types.Node {
 
 func (tmp dirEntry) create(l *log.Logger, u util.Util) error {
 	d := types.Directory(tmp)
-	st, err := os.Lstat(d.Path)
+	st, err := u.CheckNode(d.Path, util.NodeDirectory, util.PolicyFor(d.Node))
+	var conflict util.NodeConflictError
 	switch {
-	case os.IsNotExist(err):
+	case errors.As(err, &conflict) && conflict.Kept:
+		l.Warning("not creating directory %q: %v", d.Path, err)
+		return nil
+	case err != nil:
+		return fmt.Errorf("error creating directory %s: %v", d.Path, err)
+	case st == nil:
 		// use default perms, we'll fix it later
-		if err := os.MkdirAll(d.Path, util.DefaultDirectoryPermissions); err != nil {
+		if err := u.FS().MkdirAll(d.Path, util.DefaultDirectoryPermissions); err != nil {
 			return fmt.Errorf("failed to create directory %s: %v", d.Path, err)
 		}
-	case err != nil:
-		return fmt.Errorf("stat() failed on %s: %v", d.Path, err)
-	case !st.Mode().IsDir():
-		return fmt.Errorf("error creating directory %s: A non-directory already exists and overwrite is false", d.Path)
 	}
 
 	if err := u.SetPermissions(d.Mode, d.Node); err != nil {
@@ -354,7 +595,7 @@ func (tmp linkEntry) node() types.Node {
 func (tmp linkEntry) create(l *log.Logger, u util.Util) error {
 	s := types.Link(tmp)
 	hard := cutil.IsTrue(s.Hard)
-	st, err := os.Lstat(s.Path)
+	st, err := u.FS().Lstat(s.Path)
 	switch {
 	case os.IsNotExist(err):
 		break
@@ -366,19 +607,37 @@ func (tmp linkEntry) create(l *log.Logger, u util.Util) error {
 		if err != nil {
 			return fmt.Errorf("error resolving target path of hard link %s: %v", s.Path, err)
 		}
-		targetst, err := os.Lstat(targetPath)
+		targetst, err := u.FS().Lstat(targetPath)
 		if err != nil {
 			return fmt.Errorf("error creating hard link %s: target does not exist or stat() returned an err: %v", s.Path, err)
 		}
-		if !os.SameFile(st, targetst) {
+		if util.SameFile(st, targetst) {
+			l.Info("Hardlink %s to %s already exists, doing nothing", s.Path, *s.Target)
+			return nil
+		}
+		switch util.PolicyFor(s.Node) {
+		case util.ConflictReplace:
+			if err := u.FS().RemoveAll(s.Path); err != nil {
+				return fmt.Errorf("error creating hard link %s: removing existing node: %v", s.Path, err)
+			}
+		case util.ConflictKeep:
+			l.Warning("not creating hard link %q: a node that is not the target exists there; keeping it since onConflict is keep", s.Path)
+			return nil
+		default:
 			return fmt.Errorf("error creating hard link %s: a file already exists at that path but is not the target and overwrite is false", s.Path)
 		}
-		l.Info("Hardlink %s to %s already exists, doing nothing", s.Path, *s.Target)
-		return nil
 	case !hard:
+		existing, err := u.CheckNode(s.Path, util.NodeSymlink, util.PolicyFor(s.Node))
+		var conflict util.NodeConflictError
+		if errors.As(err, &conflict) && conflict.Kept {
+			l.Warning("not creating symlink %q: %v", s.Path, err)
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error creating symlink %s: %v", s.Path, err)
+		}
 		// if the existing file is a symlink, check that its target is correct
-		if st.Mode()&os.ModeSymlink != 0 {
-			if target, err := os.Readlink(s.Path); err != nil {
+		if existing != nil {
+			if target, err := u.FS().Readlink(s.Path); err != nil {
 				return fmt.Errorf("error reading link at %s: %v", s.Path, err)
 			} else if filepath.Clean(target) != filepath.Clean(*s.Target) {
 				return fmt.Errorf("error creating symlink %s: a symlink exists at that path but points to %s, not %s and overwrite is false", s.Path, target, *s.Target)
@@ -387,7 +646,6 @@ func (tmp linkEntry) create(l *log.Logger, u util.Util) error {
 				return nil
 			}
 		}
-		return fmt.Errorf("error creating symlink %s: a non-symlink already exists at that path and overwrite is false", s.Path)
 	}
 
 	if err := l.LogOp(
@@ -413,8 +671,11 @@ func (s stage) getOrderedCreationList(config types.Config) ([]filesystemEntry, e
 		if err != nil {
 			return nil, err
 		}
+		if err := util.CheckPathLimits(path, false); err != nil {
+			return nil, err
+		}
 		if existing, ok := paths[path]; ok {
-			return nil, fmt.Errorf("directory at %s resolved to %s after symlink chasing, but another entry with path %s also resolves there",
+			return nil, fmt.Errorf("directory at %q resolved to %q after symlink chasing, but another entry with path %q also resolves there",
 				d.Path, path, existing)
 		}
 		paths[path] = d.Path
@@ -423,12 +684,15 @@ func (s stage) getOrderedCreationList(config types.Config) ([]filesystemEntry, e
 	}
 
 	for _, f := range config.Storage.Files {
-		path, err := s.JoinPath(f.Path)
+		path, err := s.filePath(f)
 		if err != nil {
 			return nil, err
 		}
+		if err := util.CheckPathLimits(path, true); err != nil {
+			return nil, err
+		}
 		if existing, ok := paths[path]; ok {
-			return nil, fmt.Errorf("file at %s resolved to %s after symlink chasing, but another entry with path %s also resolves there",
+			return nil, fmt.Errorf("file at %q resolved to %q after symlink chasing, but another entry with path %q also resolves there",
 				f.Path, path, existing)
 		}
 		paths[path] = f.Path
@@ -442,8 +706,11 @@ func (s stage) getOrderedCreationList(config types.Config) ([]filesystemEntry, e
 		if err != nil {
 			return nil, err
 		}
+		if err := util.CheckPathLimits(path, false); err != nil {
+			return nil, err
+		}
 		if existing, ok := paths[path]; ok {
-			return nil, fmt.Errorf("link at %s resolved to %s after symlink chasing, but another entry with path %s also resolves there",
+			return nil, fmt.Errorf("link at %q resolved to %q after symlink chasing, but another entry with path %q also resolves there",
 				l.Path, path, existing)
 		}
 		paths[path] = l.Path
@@ -465,9 +732,20 @@ func (s stage) getOrderedCreationList(config types.Config) ([]filesystemEntry, e
 	return entries, nil
 }
 
+// filePath returns the path to create the file entry f at. Devices are
+// written in the running system's /dev, since the target's isn't populated.
+func (s stage) filePath(f types.File) (string, error) {
+	if cutil.IsTrue(f.Device) {
+		return f.Path, nil
+	}
+	return s.JoinPath(f.Path)
+}
+
+// removePathOnOverwrite deletes any existing node at the path of e if its
+// overwrite field is set, so that creating it doesn't conflict.
 func (s *stage) removePathOnOverwrite(e filesystemEntry) error {
 	if cutil.IsTrue(e.node().Overwrite) {
-		return os.RemoveAll(e.node().Path)
+		return s.FS().RemoveAll(e.node().Path)
 	}
 	return nil
 }
@@ -479,7 +757,7 @@ func (s *stage) relabelPath(path string) error {
 		return nil
 	}
 
-	missingPath, err := util.FindFirstMissingPathComponent(path)
+	missingPath, err := s.FindFirstMissingPathComponent(path)
 	if err != nil {
 		return err
 	}
@@ -496,18 +774,27 @@ func (s *stage) createEntries(entries []filesystemEntry) error {
 
 	for _, e := range entries {
 		path := e.node().Path
+		if f, ok := e.(fileEntry); ok && cutil.IsTrue(f.Device) {
+			if err := e.create(s.Logger, s.Util); err != nil {
+				return fmt.Errorf("error writing device %q: %v", path, err)
+			}
+			continue
+		}
 		if !strings.HasPrefix(path, s.DestDir) {
 			panic(fmt.Sprintf("Entry path %s isn't under prefix %s", path, s.DestDir))
 		}
 
 		if err := s.relabelPath(path); err != nil {
-			return fmt.Errorf("error relabeling paths for %s: %v", path, err)
+			return fmt.Errorf("error relabeling paths for %q: %v", path, err)
 		}
 		if err := s.removePathOnOverwrite(e); err != nil {
-			return fmt.Errorf("error removing existing file %s: %v", path, err)
+			return fmt.Errorf("error removing existing file %q: %v", path, err)
 		}
 		if err := e.create(s.Logger, s.Util); err != nil {
-			return fmt.Errorf("error creating %s: %v", path, err)
+			return fmt.Errorf("error creating %q: %v", path, err)
+		}
+		if _, ok := e.(fileEntry); ok {
+			s.recordWritten(path)
 		}
 	}
 	return nil
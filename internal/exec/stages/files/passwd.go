@@ -68,6 +68,10 @@ func (s *stage) createPasswd(config types.Config) error {
 
 		s.relabel(deglobbed...)
 		s.relabel("/etc/.pwd.lock")
+		// the databases edited by useradd, groupadd, and friends
+		for _, path := range []string{"/etc/passwd", "/etc/group", "/etc/shadow", "/etc/gshadow"} {
+			s.recordWritten(filepath.Join(s.DestDir, path))
+		}
 		for _, user := range config.Passwd.Users {
 			if util.IsTrue(user.NoCreateHome) {
 				continue
@@ -100,7 +104,7 @@ func (s *stage) createPasswd(config types.Config) error {
 
 // ensureUsers ensures that users match the state described
 // in config.Passwd.Users.
-func (s stage) ensureUsers(config types.Config) error {
+func (s *stage) ensureUsers(config types.Config) error {
 	if len(config.Passwd.Users) == 0 {
 		return nil
 	}
@@ -131,6 +135,13 @@ func (s stage) ensureUsers(config types.Config) error {
 			return fmt.Errorf("failed to add keys to user %q: %v",
 				u.Name, err)
 		}
+		if len(u.SSHAuthorizedKeys) > 0 {
+			path, err := s.AuthorizedKeysPath(u)
+			if err != nil {
+				return err
+			}
+			s.recordWritten(path)
+		}
 	}
 
 	return nil
@@ -21,6 +21,8 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/coreos/go-systemd/v22/unit"
+
 	"github.com/coreos/ignition/v2/config/shared/errors"
 	cutil "github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
@@ -28,6 +30,10 @@ import (
 	"github.com/coreos/ignition/v2/internal/systemd"
 )
 
+// fsckDropinName is the name of the drop-in fsckMountUnits adds to mount
+// units.
+const fsckDropinName = "ignition-fsck.conf"
+
 // Preset holds the information about
 // a given systemd unit.
 type Preset struct {
@@ -50,8 +56,15 @@ func (s *stage) warnOnOldSystemdVersion() error {
 	return nil
 }
 
-// createUnits creates the units listed under systemd.units.
+// createUnits creates the units listed under systemd.units, and the fsck
+// drop-ins for the mount units of filesystems.
 func (s *stage) createUnits(config types.Config) error {
+	for _, unit := range fsckMountUnits(config.Storage.Filesystems, config.Systemd.Units) {
+		if err := s.writeSystemdUnit(unit); err != nil {
+			return err
+		}
+	}
+
 	presets := make(map[string]*Preset)
 	for _, unit := range config.Systemd.Units {
 		if err := s.writeSystemdUnit(unit); err != nil {
@@ -128,6 +141,43 @@ func (s *stage) createUnits(config types.Config) error {
 	return nil
 }
 
+// fsckMountUnits returns units with drop-ins making the mount units of
+// filesystems with a nonzero fsck pass require systemd-fsck for their
+// device, as systemd-fstab-generator does for fstab entries with a nonzero
+// passno. The mount unit is the one for the filesystem's path, which by
+// convention is also its mount point in the real root. Drop-ins are only
+// returned for mount units defined in configUnits, so that a stray drop-in
+// doesn't end up on a unit that doesn't exist. The root filesystem is
+// checked by the initramfs, so it's skipped.
+func fsckMountUnits(filesystems []types.Filesystem, configUnits []types.Unit) []types.Unit {
+	defined := make(map[string]struct{})
+	for _, u := range configUnits {
+		defined[u.Name] = struct{}{}
+	}
+	var units []types.Unit
+	for _, fs := range filesystems {
+		if fs.FsckPass == nil || *fs.FsckPass == 0 || cutil.NilOrEmpty(fs.Path) || *fs.Path == "/" {
+			continue
+		}
+		name := unit.UnitNamePathEscape(*fs.Path) + ".mount"
+		if _, ok := defined[name]; !ok {
+			continue
+		}
+		fsck := fmt.Sprintf("systemd-fsck@%s.service", unit.UnitNamePathEscape(fs.Device))
+		contents := fmt.Sprintf("# Generated by Ignition for a filesystem with fsckPass %d\n[Unit]\nRequires=%s\nAfter=%s\n", *fs.FsckPass, fsck, fsck)
+		units = append(units, types.Unit{
+			Name: name,
+			Dropins: []types.Dropin{
+				{
+					Name:     fsckDropinName,
+					Contents: &contents,
+				},
+			},
+		})
+	}
+	return units
+}
+
 // parseInstanceUnit extracts the name and a corresponding instance
 // for a given instantiated unit.
 // e.g: echo@bar.service ==> unitName=echo@.service & instance=bar
@@ -187,6 +237,11 @@ func (s *stage) createSystemdPresetFile(presets map[string]*Preset) error {
 			}
 		}
 	}
+	presetPath, err := s.JoinPath(util.PresetPath)
+	if err != nil {
+		return err
+	}
+	s.recordWritten(presetPath)
 	// Print the warning if there's an instantiated unit present under
 	// the systemd units and the version of systemd in a given system
 	// is older than 240.
@@ -224,6 +279,7 @@ func (s *stage) writeSystemdUnit(unit types.Unit) error {
 			); err != nil {
 				return err
 			}
+			s.recordWritten(f.Node.Path)
 			if !relabeledDropinDir {
 				s.relabel(filepath.Dir(relabelPath))
 				relabeledDropinDir = true
@@ -250,6 +306,7 @@ func (s *stage) writeSystemdUnit(unit types.Unit) error {
 		); err != nil {
 			return err
 		}
+		s.recordWritten(f.Node.Path)
 		s.relabel(relabelPath)
 
 		return nil
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
+	cutil "github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
 )
 
@@ -74,3 +75,65 @@ func TestParseInstanceUnit(t *testing.T) {
 		}
 	}
 }
+
+func TestFsckMountUnits(t *testing.T) {
+	filesystems := []types.Filesystem{
+		{
+			Device:   "/dev/disk/by-label/data",
+			Format:   cutil.StrToPtr("ext4"),
+			Path:     cutil.StrToPtr("/var/lib/data"),
+			FsckPass: cutil.IntToPtr(2),
+		},
+		// explicitly not checked
+		{
+			Device:   "/dev/sdb",
+			Format:   cutil.StrToPtr("ext4"),
+			Path:     cutil.StrToPtr("/srv"),
+			FsckPass: cutil.IntToPtr(0),
+		},
+		// not mounted
+		{
+			Device:   "/dev/sdc",
+			Format:   cutil.StrToPtr("xfs"),
+			FsckPass: cutil.IntToPtr(2),
+		},
+		// checked by the initramfs
+		{
+			Device:   "/dev/disk/by-label/root",
+			Format:   cutil.StrToPtr("xfs"),
+			Path:     cutil.StrToPtr("/"),
+			FsckPass: cutil.IntToPtr(1),
+		},
+		// no mount unit in the config
+		{
+			Device:   "/dev/sdd",
+			Format:   cutil.StrToPtr("ext4"),
+			Path:     cutil.StrToPtr("/var/log"),
+			FsckPass: cutil.IntToPtr(2),
+		},
+	}
+	configUnits := []types.Unit{
+		{Name: "var-lib-data.mount"},
+		{Name: "srv.mount"},
+		{Name: "-.mount"},
+		{Name: "var-log.service"},
+	}
+	contents := "# Generated by Ignition for a filesystem with fsckPass 2\n" +
+		"[Unit]\n" +
+		"Requires=systemd-fsck@dev-disk-by\\x2dlabel-data.service\n" +
+		"After=systemd-fsck@dev-disk-by\\x2dlabel-data.service\n"
+	expected := []types.Unit{
+		{
+			Name: "var-lib-data.mount",
+			Dropins: []types.Dropin{
+				{
+					Name:     fsckDropinName,
+					Contents: &contents,
+				},
+			},
+		},
+	}
+	if units := fsckMountUnits(filesystems, configUnits); !reflect.DeepEqual(expected, units) {
+		t.Errorf("bad units: want %+v, got %+v", expected, units)
+	}
+}
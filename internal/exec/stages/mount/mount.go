@@ -77,6 +77,10 @@ func (s stage) Apply(config types.Config, ignoreUnsupported bool) error {
 }
 
 func (s stage) Run(config types.Config) error {
+	if err := s.checkFilesystems(config.Storage.Filesystems); err != nil {
+		return err
+	}
+
 	fss := []types.Filesystem{}
 	for _, fs := range config.Storage.Filesystems {
 		if cutil.NotEmpty(fs.Path) {
@@ -92,6 +96,72 @@ func (s stage) Run(config types.Config) error {
 	return nil
 }
 
+// fsck exit code bits
+const (
+	fsckErrorsCorrected = 1
+	fsckRebootRequired  = 2
+	fsckUncorrected     = 4
+)
+
+// checkFilesystems runs fsck on every filesystem with a nonzero fsck pass
+// number, checking all pass 1 filesystems before pass 2 filesystems.
+func (s stage) checkFilesystems(filesystems []types.Filesystem) error {
+	fss := []types.Filesystem{}
+	for _, fs := range filesystems {
+		if fs.FsckPass != nil && *fs.FsckPass > 0 {
+			fss = append(fss, fs)
+		}
+	}
+	sort.SliceStable(fss, func(i, j int) bool { return *fss[i].FsckPass < *fss[j].FsckPass })
+	for _, fs := range fss {
+		if err := s.checkFs(fs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s stage) checkFs(fs types.Filesystem) error {
+	if fs.Format == nil || *fs.Format == "swap" || *fs.Format == "" || *fs.Format == "none" {
+		return nil
+	}
+
+	cmd := exec.Command(distro.FsckCmd(), "-a", "-t", *fs.Format, fs.Device)
+	code, err := s.Logger.LogCmd(cmd,
+		"checking %q filesystem on %q (pass %d)", *fs.Format, fs.Device, *fs.FsckPass,
+	)
+	rebootRequested, err := fsckResult(fs.Device, code, err)
+	if err != nil {
+		return err
+	}
+	if rebootRequested {
+		// fsck asks for a reboot when it corrected a mounted
+		// filesystem, but we only check filesystems before mounting
+		// them, so the kernel has no stale view of it to discard
+		s.Logger.Warning("fsck of %q corrected errors and requested a reboot; continuing since the filesystem isn't mounted", fs.Device)
+	}
+	return nil
+}
+
+// fsckResult interprets the exit code and error of running fsck on device.
+// fsck's exit code is a bitmask; see fsck(8). A negative code means fsck
+// couldn't be run. It returns whether fsck requested a reboot after
+// correcting errors, or an error if the filesystem can't be used.
+func fsckResult(device string, code int, err error) (bool, error) {
+	switch {
+	case err == nil, code == fsckErrorsCorrected:
+		return false, nil
+	case code < 0:
+		return false, fmt.Errorf("running fsck on %q: %v", device, err)
+	case code&fsckUncorrected != 0:
+		return false, fmt.Errorf("fsck of %q found errors it couldn't correct; repair the filesystem manually: %v", device, err)
+	case code&^(fsckErrorsCorrected|fsckRebootRequired) == 0:
+		return true, nil
+	default:
+		return false, fmt.Errorf("fsck of %q failed with exit code %d: %v", device, code, err)
+	}
+}
+
 // checkForNonDirectories returns an error if any element of path is not a directory
 func checkForNonDirectories(path string) error {
 	p := "/"
@@ -124,7 +194,7 @@ func (s stage) mountFs(fs types.Filesystem) error {
 	}
 
 	if _, err := os.Stat(path); err != nil && os.IsNotExist(err) {
-		firstMissing, err := util.FindFirstMissingPathComponent(path)
+		firstMissing, err := s.FindFirstMissingPathComponent(path)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,59 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mount
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFsckResult(t *testing.T) {
+	exitErr := errors.New("exit status")
+	tests := []struct {
+		code   int
+		err    error
+		reboot bool
+		fails  bool
+	}{
+		// success; LogCmd leaves the code at -1
+		{-1, nil, false, false},
+		{0, nil, false, false},
+		// errors corrected
+		{1, exitErr, false, false},
+		// reboot requested
+		{2, exitErr, true, false},
+		{3, exitErr, true, false},
+		// errors left uncorrected
+		{4, exitErr, false, true},
+		{5, exitErr, false, true},
+		{6, exitErr, false, true},
+		// operational error
+		{8, exitErr, false, true},
+		// usage error
+		{16, exitErr, false, true},
+		// fsck couldn't be run
+		{-1, errors.New("executable file not found"), false, true},
+	}
+
+	for i, test := range tests {
+		reboot, err := fsckResult("/dev/sda1", test.code, test.err)
+		if reboot != test.reboot {
+			t.Errorf("#%d: expected reboot requested %v, got %v", i, test.reboot, reboot)
+		}
+		if (err != nil) != test.fails {
+			t.Errorf("#%d: expected failure %v, got error %v", i, test.fails, err)
+		}
+	}
+}
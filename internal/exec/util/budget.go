@@ -0,0 +1,41 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
+)
+
+// SkipOptional reports whether an optional operation, i.e. one the node can
+// boot without, should be skipped because the provisioning budget set in
+// config has run out. If so, it logs a warning and records the operation
+// in the state so it's listed in the result file.
+func (u Util) SkipOptional(config types.Config, format string, a ...interface{}) bool {
+	budget := config.Ignition.Timeouts.ProvisioningBudget
+	if budget == nil || *budget <= 0 || u.State == nil || u.State.ProvisioningStart.IsZero() {
+		return false
+	}
+	elapsed := time.Since(u.State.ProvisioningStart)
+	if elapsed < time.Duration(*budget)*time.Second {
+		return false
+	}
+	op := fmt.Sprintf(format, a...)
+	u.Warning("provisioning budget of %ds exceeded after %s; skipping %s", *budget, elapsed.Truncate(time.Second), op)
+	u.State.SkippedOperations = append(u.State.SkippedOperations, op)
+	return true
+}
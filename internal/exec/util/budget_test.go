@@ -0,0 +1,77 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/state"
+)
+
+func TestSkipOptional(t *testing.T) {
+	tests := []struct {
+		budget  *int
+		elapsed time.Duration
+		skip    bool
+	}{
+		// no budget
+		{
+			elapsed: time.Hour,
+		},
+		{
+			budget:  cutil.IntToPtr(0),
+			elapsed: time.Hour,
+		},
+		{
+			budget:  cutil.IntToPtr(600),
+			elapsed: time.Minute,
+		},
+		{
+			budget:  cutil.IntToPtr(600),
+			elapsed: time.Hour,
+			skip:    true,
+		},
+	}
+
+	logger := log.New(true)
+	defer logger.Close()
+	for i, test := range tests {
+		config := types.Config{
+			Ignition: types.Ignition{
+				Timeouts: types.Timeouts{ProvisioningBudget: test.budget},
+			},
+		}
+		u := Util{
+			Logger: &logger,
+			State:  &state.State{ProvisioningStart: time.Now().Add(-test.elapsed)},
+		}
+		if skip := u.SkipOptional(config, "test %d", i); skip != test.skip {
+			t.Errorf("#%d: expected skip %v, got %v", i, test.skip, skip)
+		}
+		var expected []string
+		if test.skip {
+			expected = []string{fmt.Sprintf("test %d", i)}
+		}
+		if !reflect.DeepEqual(expected, u.State.SkippedOperations) {
+			t.Errorf("#%d: bad skipped operations: expected %v, got %v", i, expected, u.State.SkippedOperations)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
+)
+
+// NodeKind is the type of a filesystem node.
+type NodeKind string
+
+const (
+	NodeFile      NodeKind = "regular file"
+	NodeDirectory NodeKind = "directory"
+	NodeSymlink   NodeKind = "symlink"
+	NodeDevice    NodeKind = "device"
+	NodeOther     NodeKind = "special file"
+)
+
+// KindOf returns the kind of node described by mode.
+func KindOf(mode os.FileMode) NodeKind {
+	switch {
+	case mode.IsRegular():
+		return NodeFile
+	case mode.IsDir():
+		return NodeDirectory
+	case mode&os.ModeSymlink != 0:
+		return NodeSymlink
+	case mode&os.ModeDevice != 0:
+		return NodeDevice
+	default:
+		return NodeOther
+	}
+}
+
+// ConflictPolicy is what to do when a node of the wrong kind already exists
+// at a path where an entry is to be created.
+type ConflictPolicy int
+
+const (
+	// ConflictFail fails the entry, leaving the existing node in place.
+	ConflictFail ConflictPolicy = iota
+	// ConflictReplace deletes the existing node, including the contents
+	// of a directory, and creates the entry.
+	ConflictReplace
+	// ConflictKeep leaves the existing node in place and skips the entry.
+	ConflictKeep
+)
+
+// PolicyFor returns the conflict policy of node, as selected by its
+// onConflict field. Entries with overwrite set have had any existing node
+// removed beforehand, so their default doesn't matter.
+func PolicyFor(node types.Node) ConflictPolicy {
+	if node.OnConflict == nil {
+		return ConflictFail
+	}
+	switch *node.OnConflict {
+	case "replace":
+		return ConflictReplace
+	case "keep":
+		return ConflictKeep
+	default:
+		return ConflictFail
+	}
+}
+
+// NodeConflictError is returned when a node of the wrong kind exists at a
+// path and the policy doesn't allow replacing it. If Kept is set, the
+// policy is ConflictKeep and the entry should be skipped rather than failed.
+type NodeConflictError struct {
+	Path  string
+	Want  NodeKind
+	Found NodeKind
+	Kept  bool
+}
+
+func (e NodeConflictError) Error() string {
+	if e.Kept {
+		return fmt.Sprintf("a %s exists at %q where a %s was requested; keeping it since onConflict is keep", e.Found, e.Path, e.Want)
+	}
+	return fmt.Sprintf("a %s exists at %q where a %s was requested, and overwrite is false", e.Found, e.Path, e.Want)
+}
+
+// CheckNode applies policy to the node at path, if any, which should be of
+// kind want. It returns the existing node's info if it's of the right kind,
+// nil if there's no node at path (anymore), or a NodeConflictError.
+// Symlinks at path aren't followed.
+func (u Util) CheckNode(path string, want NodeKind, policy ConflictPolicy) (os.FileInfo, error) {
+	st, err := u.FS().Lstat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("stat() failed on %s: %v", path, err)
+	}
+	found := KindOf(st.Mode())
+	if found == want {
+		return st, nil
+	}
+	if policy == ConflictReplace {
+		if err := u.FS().RemoveAll(path); err != nil {
+			return nil, fmt.Errorf("removing %s at %q: %v", found, path, err)
+		}
+		return nil, nil
+	}
+	return nil, NodeConflictError{
+		Path:  path,
+		Want:  want,
+		Found: found,
+		Kept:  policy == ConflictKeep,
+	}
+}
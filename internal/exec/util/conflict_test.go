@@ -0,0 +1,114 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckNode(t *testing.T) {
+	tests := []struct {
+		create func(path string) error
+		want   NodeKind
+		policy ConflictPolicy
+		exists bool
+		err    error
+		gone   bool
+	}{
+		// nothing there
+		{
+			create: func(string) error { return nil },
+			want:   NodeFile,
+			gone:   true,
+		},
+		// right kind
+		{
+			create: func(path string) error { return os.WriteFile(path, nil, 0644) },
+			want:   NodeFile,
+			exists: true,
+		},
+		{
+			create: func(path string) error { return os.Symlink("/nonexistent", path) },
+			want:   NodeSymlink,
+			exists: true,
+		},
+		// wrong kind
+		{
+			create: func(path string) error { return os.MkdirAll(filepath.Join(path, "child"), 0755) },
+			want:   NodeFile,
+			err:    NodeConflictError{Want: NodeFile, Found: NodeDirectory},
+		},
+		{
+			create: func(path string) error { return os.Symlink("/nonexistent", path) },
+			want:   NodeDirectory,
+			err:    NodeConflictError{Want: NodeDirectory, Found: NodeSymlink},
+		},
+		{
+			create: func(path string) error { return os.WriteFile(path, nil, 0644) },
+			want:   NodeDirectory,
+			err:    NodeConflictError{Want: NodeDirectory, Found: NodeFile},
+		},
+		// wrong kind, replaced
+		{
+			create: func(path string) error { return os.MkdirAll(filepath.Join(path, "child"), 0755) },
+			want:   NodeSymlink,
+			policy: ConflictReplace,
+			gone:   true,
+		},
+		{
+			create: func(path string) error { return os.WriteFile(path, nil, 0644) },
+			want:   NodeDirectory,
+			policy: ConflictReplace,
+			gone:   true,
+		},
+		// wrong kind, kept
+		{
+			create: func(path string) error { return os.WriteFile(path, nil, 0644) },
+			want:   NodeDirectory,
+			policy: ConflictKeep,
+			err:    NodeConflictError{Want: NodeDirectory, Found: NodeFile, Kept: true},
+		},
+		// right kind isn't replaced
+		{
+			create: func(path string) error { return os.MkdirAll(filepath.Join(path, "child"), 0755) },
+			want:   NodeDirectory,
+			policy: ConflictReplace,
+			exists: true,
+		},
+	}
+
+	for i, test := range tests {
+		path := filepath.Join(t.TempDir(), "node")
+		if err := test.create(path); err != nil {
+			t.Fatal(err)
+		}
+		if conflict, ok := test.err.(NodeConflictError); ok {
+			conflict.Path = path
+			test.err = conflict
+		}
+		st, err := Util{}.CheckNode(path, test.want, test.policy)
+		if err != test.err {
+			t.Errorf("#%d: bad error: want %v, got %v", i, test.err, err)
+		}
+		if (st != nil) != test.exists {
+			t.Errorf("#%d: bad info: want exists %v, got %v", i, test.exists, st != nil)
+		}
+		if _, err := os.Lstat(path); test.gone != os.IsNotExist(err) {
+			t.Errorf("#%d: bad result: want gone %v, got %v", i, test.gone, os.IsNotExist(err))
+		}
+	}
+}
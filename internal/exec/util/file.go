@@ -16,6 +16,7 @@ package util
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash"
 	"io"
@@ -40,12 +41,19 @@ const (
 	DefaultFilePermissions      os.FileMode = 0644
 )
 
+var (
+	ErrDeviceTooSmall = errors.New("contents are larger than the device")
+)
+
 type FetchOp struct {
 	Hash         hash.Hash
 	Url          url.URL
 	FetchOptions resource.FetchOptions
 	Append       bool
-	Node         types.Node
+	// Device writes the contents onto the existing device at Node.Path
+	// instead of replacing it.
+	Device bool
+	Node   types.Node
 }
 
 func newFetchOp(l *log.Logger, node types.Node, contents types.Resource) (FetchOp, error) {
@@ -53,6 +61,7 @@ func newFetchOp(l *log.Logger, node types.Node, contents types.Resource) (FetchO
 
 	uri, err := url.Parse(*contents.Source)
 	if err != nil {
+		l.Crit("Error parsing source URL of file %q: %v", node.Path, err)
 		return FetchOp{}, err
 	}
 
@@ -109,6 +118,7 @@ func (u Util) PrepareFetches(l *log.Logger, f types.File) ([]FetchOp, error) {
 		if base, err := newFetchOp(l, f.Node, f.Contents); err != nil {
 			return nil, err
 		} else {
+			base.Device = cutil.IsTrue(f.Device)
 			ops = append(ops, base)
 		}
 	}
@@ -128,7 +138,7 @@ func (u Util) PrepareFetches(l *log.Logger, f types.File) ([]FetchOp, error) {
 func (u Util) WriteLink(s types.Link) error {
 	path := s.Path
 
-	if err := MkdirForFile(path); err != nil {
+	if err := u.FS().MkdirAll(filepath.Dir(path), DefaultDirectoryPermissions); err != nil {
 		return fmt.Errorf("could not create leading directories: %v", err)
 	}
 
@@ -137,10 +147,10 @@ func (u Util) WriteLink(s types.Link) error {
 		if err != nil {
 			return err
 		}
-		return os.Link(targetPath, path)
+		return u.FS().Link(targetPath, path)
 	}
 
-	if err := os.Symlink(*s.Target, path); err != nil {
+	if err := u.FS().Symlink(*s.Target, path); err != nil {
 		return fmt.Errorf("could not create symlink: %v", err)
 	}
 
@@ -152,17 +162,17 @@ func (u Util) WriteLink(s types.Link) error {
 
 func (u Util) SetPermissions(mode *int, node types.Node) error {
 	if mode != nil {
-		if err := os.Chmod(node.Path, toFileMode(*mode)); err != nil {
+		if err := u.FS().Chmod(node.Path, toFileMode(*mode)); err != nil {
 			return fmt.Errorf("failed to change mode of %s: %v", node.Path, err)
 		}
 	}
 
-	defaultUid, defaultGid, _ := getFileOwnerAndMode(node.Path)
+	defaultUid, defaultGid := u.getFileOwner(node.Path)
 	uid, gid, err := u.ResolveNodeUidAndGid(node, defaultUid, defaultGid)
 	if err != nil {
 		return fmt.Errorf("failed to determine correct uid and gid for %s: %v", node.Path, err)
 	}
-	if err := os.Lchown(node.Path, uid, gid); err != nil {
+	if err := u.FS().Lchown(node.Path, uid, gid); err != nil {
 		return fmt.Errorf("failed to change ownership of %s: %v", node.Path, err)
 	}
 	return nil
@@ -190,14 +200,28 @@ func toFileMode(m int) os.FileMode {
 func (u Util) PerformFetch(f FetchOp) error {
 	path := f.Node.Path
 
-	if err := MkdirForFile(path); err != nil {
-		return err
+	if f.Device {
+		st, err := u.FS().Stat(path)
+		switch {
+		case err != nil:
+			return err
+		case st.Mode()&os.ModeCharDevice != 0:
+			return u.fetchToCharDevice(f)
+		case st.Mode()&os.ModeDevice != 0:
+			return u.fetchToBlockDevice(f)
+		default:
+			return fmt.Errorf("%q isn't a device", path)
+		}
+	}
+
+	if err := u.MkdirForFile(path); err != nil {
+		return fmt.Errorf("creating leading directories of %q: %w", path, err)
 	}
 
 	// Create a temporary file in the same directory to ensure it's on the same filesystem
 	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp")
 	if err != nil {
-		return err
+		return fmt.Errorf("creating temporary file for %q: %w", path, err)
 	}
 	defer tmp.Close()
 
@@ -217,18 +241,11 @@ func (u Util) PerformFetch(f FetchOp) error {
 	}
 
 	if f.Append {
-		// Make sure that we're appending to a file
-		finfo, err := os.Lstat(path)
-		switch {
-		case os.IsNotExist(err):
-			// No problem, we'll create it.
-			break
-		case err != nil:
-			return err
-		default:
-			if !finfo.Mode().IsRegular() {
-				return fmt.Errorf("can only append to files: %q", path)
-			}
+		// Make sure that we're appending to a file; if there's nothing
+		// there, we'll create it. Replacing a conflicting node here would
+		// silently drop the contents being appended to.
+		if _, err := u.CheckNode(path, NodeFile, ConflictFail); err != nil {
+			return fmt.Errorf("can only append to files: %v", err)
 		}
 
 		// Open with the default permissions, we'll chown/chmod it later
@@ -241,11 +258,11 @@ func (u Util) PerformFetch(f FetchOp) error {
 		if _, err = tmp.Seek(0, io.SeekStart); err != nil {
 			return err
 		}
-		if _, err = io.Copy(targetFile, tmp); err != nil {
+		if _, err = util.CopyFile(targetFile, tmp); err != nil {
 			return err
 		}
 	} else {
-		if err = os.Rename(tmp.Name(), path); err != nil {
+		if err = u.FS().Rename(tmp.Name(), path); err != nil {
 			return err
 		}
 	}
@@ -253,19 +270,112 @@ func (u Util) PerformFetch(f FetchOp) error {
 	return nil
 }
 
+// fetchToBlockDevice streams a fetch directly onto the block device at
+// f.Node.Path. The size of the contents is checked against the device before
+// it's opened for writing, so resources whose size can't be determined up
+// front are rejected. There's no temporary file to rename into place, so the
+// device will have been modified if the fetch fails partway or its hash
+// doesn't match.
+func (u Util) fetchToBlockDevice(f FetchOp) error {
+	path := f.Node.Path
+
+	if f.Append {
+		return fmt.Errorf("can't append to block device %q", path)
+	}
+	if f.Url.Scheme == "s3" || f.Url.Scheme == "arn" {
+		return fmt.Errorf("can't write S3 objects to block device %q", path)
+	}
+
+	size, err := blockDeviceSize(path)
+	if err != nil {
+		return err
+	}
+	opts := f.FetchOptions
+	opts.MaxSize = size
+
+	dev, err := os.OpenFile(path, os.O_WRONLY|unix.O_DIRECT, 0)
+	if err != nil {
+		return err
+	}
+	defer dev.Close()
+	align, err := util.BlockDeviceSectorSize(dev)
+	if err != nil {
+		return err
+	}
+	w := util.NewDirectWriter(dev, align)
+	if err := u.Fetcher.FetchToWriter(f.Url, &limitedWriter{w, size}, opts); err != nil {
+		if err == resource.ErrTooLarge {
+			err = ErrDeviceTooSmall
+		}
+		u.Crit("Error fetching file %q: %v", path, err)
+		return err
+	}
+	return w.Flush()
+}
+
+func blockDeviceSize(path string) (int64, error) {
+	dev, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer dev.Close()
+	return util.BlockDeviceSize(dev)
+}
+
+// fetchToCharDevice streams a fetch directly into the character device at
+// f.Node.Path.
+func (u Util) fetchToCharDevice(f FetchOp) error {
+	path := f.Node.Path
+
+	if f.Append {
+		return fmt.Errorf("can't append to character device %q", path)
+	}
+	if f.Url.Scheme == "s3" || f.Url.Scheme == "arn" {
+		return fmt.Errorf("can't write S3 objects to character device %q", path)
+	}
+
+	dev, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer dev.Close()
+	if err := u.Fetcher.FetchToWriter(f.Url, dev, f.FetchOptions); err != nil {
+		u.Crit("Error fetching file %q: %v", path, err)
+		return err
+	}
+	return nil
+}
+
+// limitedWriter fails writes that would go past the end of a device, so
+// oversized contents aren't silently truncated if the size reported before
+// fetching was wrong.
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		return 0, ErrDeviceTooSmall
+	}
+	n, err := l.w.Write(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
 // MkdirForFile helper creates the directory components of path.
-func MkdirForFile(path string) error {
-	return os.MkdirAll(filepath.Dir(path), DefaultDirectoryPermissions)
+func (u Util) MkdirForFile(path string) error {
+	return u.FS().MkdirAll(filepath.Dir(path), DefaultDirectoryPermissions)
 }
 
 // FindFirstMissingPathComponent returns the path up to the first component
 // which was found to be missing, or the whole path if it already exists.
-func FindFirstMissingPathComponent(path string) (string, error) {
+func (u Util) FindFirstMissingPathComponent(path string) (string, error) {
 	entry := path
 	dir := filepath.Dir(path)
 	for {
 		exists := true
-		if _, err := os.Stat(dir); err != nil && os.IsNotExist(err) {
+		if _, err := u.FS().Stat(dir); err != nil && os.IsNotExist(err) {
 			exists = false
 		} else if err != nil {
 			return "", err
@@ -312,13 +422,17 @@ func FilesystemIsEmpty(dirpath string) (bool, error) {
 
 // getFileOwner will return the uid and gid for the file at a given path. If the
 // file doesn't exist, or some other error is encountered when running stat on
-// the path, 0, 0, and 0 will be returned.
-func getFileOwnerAndMode(path string) (int, int, os.FileMode) {
-	info := unix.Stat_t{}
-	if err := unix.Stat(path, &info); err != nil {
-		return 0, 0, 0
+// the path, 0 and 0 will be returned.
+func (u Util) getFileOwner(path string) (int, int) {
+	st, err := u.FS().Stat(path)
+	if err != nil {
+		return 0, 0
+	}
+	info, ok := st.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0
 	}
-	return int(info.Uid), int(info.Gid), os.FileMode(info.Mode)
+	return int(info.Uid), int(info.Gid)
 }
 
 // ResolveNodeUidAndGid attempts to convert a types.Node into a concrete uid and
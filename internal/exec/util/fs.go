@@ -0,0 +1,83 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// FS is the set of operations on filesystem nodes used when creating
+// files, directories, links, and units and setting their permissions, so
+// that they can be run against a MemFS in unit tests. Writing file contents
+// isn't covered; fetched files are written to a temporary file on the
+// running system and renamed into place.
+type FS interface {
+	Chmod(name string, mode os.FileMode) error
+	Chown(name string, uid, gid int) error
+	Lchown(name string, uid, gid int) error
+	Link(oldname, newname string) error
+	Lstat(name string) (os.FileInfo, error)
+	MkdirAll(path string, perm os.FileMode) error
+	Readlink(name string) (string, error)
+	Remove(name string) error
+	RemoveAll(path string) error
+	Rename(oldpath, newpath string) error
+	Stat(name string) (os.FileInfo, error)
+	Statfs(path string, buf *unix.Statfs_t) error
+	Symlink(oldname, newname string) error
+}
+
+// OSFS is the FS of the running system.
+type OSFS struct{}
+
+func (OSFS) Chmod(name string, mode os.FileMode) error    { return os.Chmod(name, mode) }
+func (OSFS) Chown(name string, uid, gid int) error        { return os.Chown(name, uid, gid) }
+func (OSFS) Lchown(name string, uid, gid int) error       { return os.Lchown(name, uid, gid) }
+func (OSFS) Link(oldname, newname string) error           { return os.Link(oldname, newname) }
+func (OSFS) Lstat(name string) (os.FileInfo, error)       { return os.Lstat(name) }
+func (OSFS) MkdirAll(path string, perm os.FileMode) error { return os.MkdirAll(path, perm) }
+func (OSFS) Readlink(name string) (string, error)         { return os.Readlink(name) }
+func (OSFS) Remove(name string) error                     { return os.Remove(name) }
+func (OSFS) RemoveAll(path string) error                  { return os.RemoveAll(path) }
+func (OSFS) Rename(oldpath, newpath string) error         { return os.Rename(oldpath, newpath) }
+func (OSFS) Stat(name string) (os.FileInfo, error)        { return os.Stat(name) }
+func (OSFS) Statfs(path string, buf *unix.Statfs_t) error { return unix.Statfs(path, buf) }
+func (OSFS) Symlink(oldname, newname string) error        { return os.Symlink(oldname, newname) }
+
+// FS returns the FS to operate on, which is the running system's unless
+// u.FileSystem is set.
+func (u Util) FS() FS {
+	if u.FileSystem == nil {
+		return OSFS{}
+	}
+	return u.FileSystem
+}
+
+// SameFile is like os.SameFile, but also works for the os.FileInfo
+// returned by a MemFS.
+func SameFile(a, b os.FileInfo) bool {
+	sa, ok := a.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	sb, ok := b.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	return sa.Dev == sb.Dev && sa.Ino == sb.Ino
+}
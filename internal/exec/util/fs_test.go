@@ -0,0 +1,194 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"os"
+	"testing"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
+)
+
+func TestWriteLink(t *testing.T) {
+	fs := NewMemFS()
+	u := Util{DestDir: "/sysroot", FileSystem: fs}
+	if err := fs.MkdirAll("/sysroot/etc", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("/sysroot/etc/target", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	symlink := types.Link{
+		Node: types.Node{
+			Path:  "/sysroot/etc/a/symlink",
+			User:  types.NodeUser{ID: cutil.IntToPtr(500)},
+			Group: types.NodeGroup{ID: cutil.IntToPtr(501)},
+		},
+		LinkEmbedded1: types.LinkEmbedded1{Target: cutil.StrToPtr("/etc/target")},
+	}
+	if err := u.WriteLink(symlink); err != nil {
+		t.Fatal(err)
+	}
+	n, ok := fs.Get("/sysroot/etc/a/symlink")
+	switch {
+	case !ok:
+		t.Fatal("symlink wasn't created")
+	case n.Mode&os.ModeSymlink == 0 || n.Target != "/etc/target":
+		t.Errorf("bad symlink: mode %v, target %q", n.Mode, n.Target)
+	case n.Uid != 500 || n.Gid != 501:
+		t.Errorf("bad symlink owner: %d:%d", n.Uid, n.Gid)
+	}
+	if n, _ := fs.Get("/sysroot/etc/a"); n == nil || !n.Mode.IsDir() {
+		t.Errorf("leading directory wasn't created")
+	}
+
+	hardlink := types.Link{
+		Node:          types.Node{Path: "/sysroot/etc/hardlink"},
+		LinkEmbedded1: types.LinkEmbedded1{Target: cutil.StrToPtr("/etc/target"), Hard: cutil.BoolToPtr(true)},
+	}
+	if err := u.WriteLink(hardlink); err != nil {
+		t.Fatal(err)
+	}
+	target, _ := fs.Lstat("/sysroot/etc/target")
+	link, err := fs.Lstat("/sysroot/etc/hardlink")
+	if err != nil || !SameFile(target, link) {
+		t.Errorf("hard link doesn't refer to its target: %v", err)
+	}
+}
+
+func TestSetPermissions(t *testing.T) {
+	fs := NewMemFS()
+	u := Util{FileSystem: fs}
+	if err := fs.WriteFile("/file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Lchown("/file", 10, 20); err != nil {
+		t.Fatal(err)
+	}
+
+	// keeps the existing owner if none is given
+	if err := u.SetPermissions(cutil.IntToPtr(04750), types.Node{Path: "/file"}); err != nil {
+		t.Fatal(err)
+	}
+	n, _ := fs.Get("/file")
+	if n.Mode != os.ModeSetuid|0750 || n.Uid != 10 || n.Gid != 20 {
+		t.Errorf("bad permissions: mode %v, owner %d:%d", n.Mode, n.Uid, n.Gid)
+	}
+
+	if err := u.SetPermissions(nil, types.Node{Path: "/missing"}); err == nil {
+		t.Errorf("setting permissions of missing file succeeded")
+	}
+}
+
+func TestMaskUnit(t *testing.T) {
+	fs := NewMemFS()
+	u := Util{DestDir: "/sysroot", FileSystem: fs}
+	unit := types.Unit{Name: "foo.service"}
+	if err := fs.MkdirAll("/sysroot/etc/systemd/system", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("/sysroot/etc/systemd/system/foo.service", []byte("[Unit]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if masked, err := u.IsUnitMasked(unit); err != nil || masked {
+		t.Fatalf("unit file reported as masked: %v, %v", masked, err)
+	}
+	path, err := u.MaskUnit(unit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/etc/systemd/system/foo.service" {
+		t.Errorf("bad unit path %q", path)
+	}
+	if masked, err := u.IsUnitMasked(unit); err != nil || !masked {
+		t.Fatalf("masked unit reported as unmasked: %v, %v", masked, err)
+	}
+	if err := u.UnmaskUnit(unit); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fs.Get("/sysroot/etc/systemd/system/foo.service"); ok {
+		t.Errorf("mask wasn't removed")
+	}
+}
+
+func TestMemFSRename(t *testing.T) {
+	fs := NewMemFS()
+	for _, d := range []string{"/a/b", "/c/d"} {
+		if err := fs.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"/a/b/file", "/a/other", "/c/file"} {
+		if err := fs.WriteFile(f, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// replaces a file
+	if err := fs.Rename("/a/other", "/c/file"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := fs.Get("/c/file"); n == nil || string(n.Data) != "/a/other" {
+		t.Errorf("file wasn't replaced: %+v", n)
+	}
+	// moves a directory with its contents
+	if err := fs.Rename("/a/b", "/e"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := fs.Get("/e/file"); n == nil || string(n.Data) != "/a/b/file" {
+		t.Errorf("directory contents weren't moved: %+v", n)
+	}
+	if _, ok := fs.Get("/a/b/file"); ok {
+		t.Errorf("old directory contents still exist")
+	}
+	for _, test := range [][2]string{
+		{"/missing", "/x"},
+		{"/c/file", "/missing/x"},
+		{"/c/file", "/c/d"},
+		{"/e", "/c"},
+		{"/e", "/e/f"},
+	} {
+		if err := fs.Rename(test[0], test[1]); err == nil {
+			t.Errorf("renaming %q to %q succeeded", test[0], test[1])
+		}
+	}
+}
+
+func TestFindFirstMissingPathComponent(t *testing.T) {
+	fs := NewMemFS()
+	u := Util{FileSystem: fs}
+	if err := fs.MkdirAll("/sysroot/etc", 0755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"/sysroot/etc", "/sysroot/etc"},
+		{"/sysroot/etc/file", "/sysroot/etc/file"},
+		{"/sysroot/etc/a/b/file", "/sysroot/etc/a"},
+	}
+	for i, test := range tests {
+		out, err := u.FindFirstMissingPathComponent(test.in)
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		} else if out != test.out {
+			t.Errorf("#%d: expected %q, got %q", i, test.out, out)
+		}
+	}
+}
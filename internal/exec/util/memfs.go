@@ -0,0 +1,367 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const memFSMaxSymlinks = 40
+
+// MemFS is an in-memory FS for unit tests. Paths must be absolute. Like
+// the kernel, Stat, Chmod, and Chown follow symlinks in the last element
+// of the path, but unlike the kernel, symlinks in other elements of the
+// path aren't followed; Ignition resolves those itself with JoinPath.
+type MemFS struct {
+	nodes    map[string]*MemNode
+	lastIno  uint64
+	readOnly []string
+}
+
+// MemNode is a file, directory, or symlink in a MemFS. Hard links share
+// the same MemNode.
+type MemNode struct {
+	Mode   os.FileMode
+	Uid    int
+	Gid    int
+	Target string
+	Data   []byte
+	ino    uint64
+}
+
+// NewMemFS returns a MemFS containing only the root directory.
+func NewMemFS() *MemFS {
+	m := &MemFS{nodes: map[string]*MemNode{}}
+	m.nodes["/"] = m.newNode(os.ModeDir | 0755)
+	return m
+}
+
+// Get returns the node at name, without following symlinks.
+func (m *MemFS) Get(name string) (*MemNode, bool) {
+	n, ok := m.nodes[filepath.Clean(name)]
+	return n, ok
+}
+
+// MountReadOnly marks dir as the mount point of a separate read-only
+// filesystem. It only affects the results of Statfs and the device numbers
+// reported by Stat and Lstat; writes aren't rejected.
+func (m *MemFS) MountReadOnly(dir string) {
+	m.readOnly = append(m.readOnly, filepath.Clean(dir))
+}
+
+// WriteFile creates or replaces a regular file at name, like os.WriteFile.
+func (m *MemFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	name, err := m.resolve("open", name)
+	if err != nil {
+		return err
+	}
+	if n, ok := m.nodes[name]; ok {
+		if n.Mode.IsDir() {
+			return pathError("open", name, syscall.EISDIR)
+		}
+		n.Data = append([]byte{}, data...)
+		return nil
+	}
+	if err := m.checkParent("open", name); err != nil {
+		return err
+	}
+	n := m.newNode(perm & os.ModePerm)
+	n.Data = append([]byte{}, data...)
+	m.nodes[name] = n
+	return nil
+}
+
+func (m *MemFS) Chmod(name string, mode os.FileMode) error {
+	name, err := m.resolve("chmod", name)
+	if err != nil {
+		return err
+	}
+	n, ok := m.nodes[name]
+	if !ok {
+		return pathError("chmod", name, syscall.ENOENT)
+	}
+	n.Mode = n.Mode&os.ModeType | mode&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky)
+	return nil
+}
+
+func (m *MemFS) Chown(name string, uid, gid int) error {
+	name, err := m.resolve("chown", name)
+	if err != nil {
+		return err
+	}
+	return m.Lchown(name, uid, gid)
+}
+
+func (m *MemFS) Lchown(name string, uid, gid int) error {
+	n, ok := m.nodes[filepath.Clean(name)]
+	if !ok {
+		return pathError("lchown", name, syscall.ENOENT)
+	}
+	if uid != -1 {
+		n.Uid = uid
+	}
+	if gid != -1 {
+		n.Gid = gid
+	}
+	return nil
+}
+
+func (m *MemFS) Link(oldname, newname string) error {
+	oldname, newname = filepath.Clean(oldname), filepath.Clean(newname)
+	n, ok := m.nodes[oldname]
+	switch {
+	case !ok:
+		return linkError("link", oldname, newname, syscall.ENOENT)
+	case n.Mode.IsDir():
+		return linkError("link", oldname, newname, syscall.EPERM)
+	}
+	if _, ok := m.nodes[newname]; ok {
+		return linkError("link", oldname, newname, syscall.EEXIST)
+	}
+	if err := m.checkParent("link", newname); err != nil {
+		return err
+	}
+	m.nodes[newname] = n
+	return nil
+}
+
+func (m *MemFS) Lstat(name string) (os.FileInfo, error) {
+	name = filepath.Clean(name)
+	n, ok := m.nodes[name]
+	if !ok {
+		return nil, pathError("lstat", name, syscall.ENOENT)
+	}
+	return memFileInfo{name: filepath.Base(name), node: n, dev: m.device(name)}, nil
+}
+
+func (m *MemFS) MkdirAll(path string, perm os.FileMode) error {
+	path, err := m.resolve("mkdir", path)
+	if err != nil {
+		return err
+	}
+	if n, ok := m.nodes[path]; ok {
+		if n.Mode.IsDir() {
+			return nil
+		}
+		return pathError("mkdir", path, syscall.ENOTDIR)
+	}
+	if err := m.MkdirAll(filepath.Dir(path), perm); err != nil {
+		return err
+	}
+	m.nodes[path] = m.newNode(os.ModeDir | perm&os.ModePerm)
+	return nil
+}
+
+func (m *MemFS) Readlink(name string) (string, error) {
+	n, ok := m.nodes[filepath.Clean(name)]
+	switch {
+	case !ok:
+		return "", pathError("readlink", name, syscall.ENOENT)
+	case n.Mode&os.ModeSymlink == 0:
+		return "", pathError("readlink", name, syscall.EINVAL)
+	}
+	return n.Target, nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	name = filepath.Clean(name)
+	n, ok := m.nodes[name]
+	if !ok {
+		return pathError("remove", name, syscall.ENOENT)
+	}
+	if n.Mode.IsDir() {
+		for path := range m.nodes {
+			if isBelow(path, name) {
+				return pathError("remove", name, syscall.ENOTEMPTY)
+			}
+		}
+	}
+	delete(m.nodes, name)
+	return nil
+}
+
+func (m *MemFS) RemoveAll(path string) error {
+	path = filepath.Clean(path)
+	for p := range m.nodes {
+		if p == path || isBelow(p, path) {
+			delete(m.nodes, p)
+		}
+	}
+	return nil
+}
+
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	n, ok := m.nodes[oldpath]
+	if !ok {
+		return linkError("rename", oldpath, newpath, syscall.ENOENT)
+	}
+	if oldpath == newpath {
+		return nil
+	}
+	if isBelow(newpath, oldpath) {
+		return linkError("rename", oldpath, newpath, syscall.EINVAL)
+	}
+	if err := m.checkParent("rename", newpath); err != nil {
+		return err
+	}
+	if existing, ok := m.nodes[newpath]; ok {
+		switch {
+		case n.Mode.IsDir() && !existing.Mode.IsDir():
+			return linkError("rename", oldpath, newpath, syscall.ENOTDIR)
+		case !n.Mode.IsDir() && existing.Mode.IsDir():
+			return linkError("rename", oldpath, newpath, syscall.EISDIR)
+		}
+		// fails if newpath is a nonempty directory
+		if err := m.Remove(newpath); err != nil {
+			return err
+		}
+	}
+	for p, child := range m.nodes {
+		if isBelow(p, oldpath) {
+			delete(m.nodes, p)
+			m.nodes[filepath.Join(newpath, strings.TrimPrefix(p, oldpath))] = child
+		}
+	}
+	delete(m.nodes, oldpath)
+	m.nodes[newpath] = n
+	return nil
+}
+
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	name, err := m.resolve("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return m.Lstat(name)
+}
+
+func (m *MemFS) Statfs(path string, buf *unix.Statfs_t) error {
+	path, err := m.resolve("statfs", path)
+	if err != nil {
+		return err
+	}
+	if _, ok := m.nodes[path]; !ok {
+		return pathError("statfs", path, syscall.ENOENT)
+	}
+	*buf = unix.Statfs_t{}
+	if m.device(path) != 0 {
+		buf.Flags |= unix.ST_RDONLY
+	}
+	return nil
+}
+
+func (m *MemFS) Symlink(oldname, newname string) error {
+	newname = filepath.Clean(newname)
+	if _, ok := m.nodes[newname]; ok {
+		return linkError("symlink", oldname, newname, syscall.EEXIST)
+	}
+	if err := m.checkParent("symlink", newname); err != nil {
+		return err
+	}
+	n := m.newNode(os.ModeSymlink | 0777)
+	n.Target = oldname
+	m.nodes[newname] = n
+	return nil
+}
+
+// device returns 0 for paths on the root filesystem, or the number of the
+// innermost read-only mount containing path.
+func (m *MemFS) device(path string) uint64 {
+	var dev uint64
+	longest := ""
+	for i, dir := range m.readOnly {
+		if (path == dir || isBelow(path, dir)) && len(dir) > len(longest) {
+			dev = uint64(i + 1)
+			longest = dir
+		}
+	}
+	return dev
+}
+
+func (m *MemFS) newNode(mode os.FileMode) *MemNode {
+	m.lastIno++
+	return &MemNode{Mode: mode, ino: m.lastIno}
+}
+
+// resolve follows symlinks in the last element of name.
+func (m *MemFS) resolve(op, name string) (string, error) {
+	name = filepath.Clean(name)
+	for i := 0; i < memFSMaxSymlinks; i++ {
+		n, ok := m.nodes[name]
+		if !ok || n.Mode&os.ModeSymlink == 0 {
+			return name, nil
+		}
+		if filepath.IsAbs(n.Target) {
+			name = filepath.Clean(n.Target)
+		} else {
+			name = filepath.Join(filepath.Dir(name), n.Target)
+		}
+	}
+	return "", pathError(op, name, syscall.ELOOP)
+}
+
+func (m *MemFS) checkParent(op, name string) error {
+	parent, err := m.resolve(op, filepath.Dir(name))
+	if err != nil {
+		return err
+	}
+	n, ok := m.nodes[parent]
+	switch {
+	case !ok:
+		return pathError(op, name, syscall.ENOENT)
+	case !n.Mode.IsDir():
+		return pathError(op, name, syscall.ENOTDIR)
+	}
+	return nil
+}
+
+func isBelow(path, dir string) bool {
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/")
+}
+
+func pathError(op, path string, err error) error {
+	return &os.PathError{Op: op, Path: path, Err: err}
+}
+
+func linkError(op, oldname, newname string, err error) error {
+	return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+}
+
+type memFileInfo struct {
+	name string
+	node *MemNode
+	dev  uint64
+}
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return int64(len(fi.node.Data)) }
+func (fi memFileInfo) Mode() os.FileMode  { return fi.node.Mode }
+func (fi memFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi memFileInfo) IsDir() bool        { return fi.node.Mode.IsDir() }
+func (fi memFileInfo) Sys() interface{} {
+	return &syscall.Stat_t{
+		Dev: fi.dev,
+		Ino: fi.node.ino,
+		Uid: uint32(fi.node.Uid),
+		Gid: uint32(fi.node.Gid),
+	}
+}
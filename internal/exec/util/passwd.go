@@ -16,7 +16,6 @@ package util
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
 	"os/user"
 	"path/filepath"
@@ -216,12 +215,12 @@ func (u Util) ModifyHomeDirPermissions(c types.PasswdUser) error {
 			if err != nil {
 				return fmt.Errorf("calculating path of %q: %w", dir, err)
 			}
-			if err := os.Chown(dir, uid, gid); err != nil {
+			if err := u.FS().Chown(dir, uid, gid); err != nil {
 				return fmt.Errorf("changing ownership of %q: %w", dir, err)
 			}
 			// more restrictive than necessary for some subdirs,
 			// but default to secure
-			if err := os.Chmod(dir, 0700); err != nil {
+			if err := u.FS().Chmod(dir, 0700); err != nil {
 				return fmt.Errorf("changing file mode of %q: %w", dir, err)
 			}
 		}
@@ -252,17 +251,9 @@ func (u Util) AuthorizeSSHKeys(c types.PasswdUser) error {
 		if !strings.HasSuffix(ks, "\n") {
 			ks = ks + "\n"
 		}
-		var path string
-		if distro.WriteAuthorizedKeysFragment() {
-			path, err = u.JoinPath(usr.HomeDir, ".ssh", "authorized_keys.d", "ignition")
-			if err == nil {
-				err = writeAuthKeysFile(usr, path, []byte(ks))
-			}
-		} else {
-			path, err = u.JoinPath(usr.HomeDir, ".ssh", "authorized_keys")
-			if err == nil {
-				err = writeAuthKeysFile(usr, path, []byte(ks))
-			}
+		path, err := u.authorizedKeysPath(usr)
+		if err == nil {
+			err = writeAuthKeysFile(usr, path, []byte(ks))
 		}
 		if err != nil {
 			return fmt.Errorf("failed to set SSH key: %v", err)
@@ -276,6 +267,23 @@ func (u Util) AuthorizeSSHKeys(c types.PasswdUser) error {
 	}, "adding ssh keys to user %q", c.Name)
 }
 
+// AuthorizedKeysPath returns the path of the file AuthorizeSSHKeys writes
+// the keys of the specified user to.
+func (u Util) AuthorizedKeysPath(c types.PasswdUser) (string, error) {
+	usr, err := u.userLookup(c.Name)
+	if err != nil {
+		return "", fmt.Errorf("unable to lookup user %q", c.Name)
+	}
+	return u.authorizedKeysPath(usr)
+}
+
+func (u Util) authorizedKeysPath(usr *user.User) (string, error) {
+	if distro.WriteAuthorizedKeysFragment() {
+		return u.JoinPath(usr.HomeDir, ".ssh", "authorized_keys.d", "ignition")
+	}
+	return u.JoinPath(usr.HomeDir, ".ssh", "authorized_keys")
+}
+
 // golang--
 func translateV2_1SSHAuthorizedKeySliceToStringSlice(keys []types.SSHAuthorizedKey) []string {
 	newKeys := make([]string, len(keys))
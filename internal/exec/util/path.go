@@ -15,9 +15,38 @@
 package util
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
 )
 
+// tempFileNameMax is the length of the longest name os.CreateTemp can
+// choose for the "tmp" pattern used by PerformFetch.
+const tempFileNameMax = len("tmp") + 10
+
+// CheckPathLimits returns an error if path, or the temporary file
+// PerformFetch creates next to it if isFile is set, exceeds the kernel's
+// PATH_MAX or NAME_MAX. Config validation only checks the path relative to
+// the destination directory, so this lets callers fail before writing
+// anything rather than partway through.
+func CheckPathLimits(path string, isFile bool) error {
+	longest := len(path)
+	if tmp := len(filepath.Dir(path)) + 1 + tempFileNameMax; isFile && tmp > longest {
+		longest = tmp
+	}
+	if longest >= unix.PathMax {
+		return fmt.Errorf("path %q is too long: writing it needs %d bytes, more than the limit of %d", path, longest, unix.PathMax-1)
+	}
+	for _, component := range strings.Split(path, "/") {
+		if len(component) > unix.NAME_MAX {
+			return fmt.Errorf("path %q has a component of %d bytes, more than the limit of %d", path, len(component), unix.NAME_MAX)
+		}
+	}
+	return nil
+}
+
 func SystemdUnitsPath() string {
 	return filepath.Join("etc", "systemd", "system")
 }
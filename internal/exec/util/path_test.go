@@ -0,0 +1,67 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPathLimits(t *testing.T) {
+	// 16 components of 255 bytes make a 4096-byte path
+	deep := strings.Repeat("/"+strings.Repeat("a", 255), 15)
+	tests := []struct {
+		path   string
+		isFile bool
+		fail   bool
+	}{
+		{
+			path: "/sysroot/etc/file\nwith a newline/ünicøde",
+		},
+		{
+			path: deep + "/" + strings.Repeat("b", 254),
+		},
+		{
+			path: deep + "/" + strings.Repeat("b", 255),
+			fail: true,
+		},
+		// the temporary file doesn't fit next to it
+		{
+			path:   deep + "/" + strings.Repeat("b", 244) + "/c",
+			isFile: true,
+			fail:   true,
+		},
+		{
+			path: deep + "/" + strings.Repeat("b", 244) + "/c",
+		},
+		{
+			path:   deep + "/" + strings.Repeat("b", 240) + "/c",
+			isFile: true,
+		},
+		{
+			path: "/sysroot/" + strings.Repeat("a", 256),
+			fail: true,
+		},
+	}
+
+	for i, test := range tests {
+		err := CheckPathLimits(test.path, test.isFile)
+		if test.fail && err == nil {
+			t.Errorf("#%d: expected error", i)
+		} else if !test.fail && err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		}
+	}
+}
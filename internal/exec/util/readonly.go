@@ -0,0 +1,164 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// writableAlternatives maps prefixes of paths commonly found on read-only
+// filesystems to the writable locations that override them. Only the
+// directories under /usr/lib whose contents are overridden by the
+// matching directory under /etc are listed.
+var writableAlternatives = []struct {
+	prefix      string
+	alternative string
+}{
+	{"/usr/lib/systemd/system/", "/etc/systemd/system/"},
+	{"/usr/lib/systemd/system-preset/", "/etc/systemd/system-preset/"},
+	{"/usr/lib/systemd/user/", "/etc/systemd/user/"},
+	{"/usr/lib/systemd/user-preset/", "/etc/systemd/user-preset/"},
+	{"/usr/lib/systemd/network/", "/etc/systemd/network/"},
+	{"/usr/lib/systemd/system.conf.d/", "/etc/systemd/system.conf.d/"},
+	{"/usr/lib/systemd/user.conf.d/", "/etc/systemd/user.conf.d/"},
+	{"/usr/lib/systemd/journald.conf.d/", "/etc/systemd/journald.conf.d/"},
+	{"/usr/lib/systemd/logind.conf.d/", "/etc/systemd/logind.conf.d/"},
+	{"/usr/lib/systemd/networkd.conf.d/", "/etc/systemd/networkd.conf.d/"},
+	{"/usr/lib/systemd/resolved.conf.d/", "/etc/systemd/resolved.conf.d/"},
+	{"/usr/lib/systemd/timesyncd.conf.d/", "/etc/systemd/timesyncd.conf.d/"},
+	{"/usr/lib/sysctl.d/", "/etc/sysctl.d/"},
+	{"/usr/lib/tmpfiles.d/", "/etc/tmpfiles.d/"},
+	{"/usr/lib/udev/rules.d/", "/etc/udev/rules.d/"},
+	{"/usr/local/", "/var/usrlocal/"},
+	{"/opt/", "/var/opt/"},
+}
+
+// ReadOnlyError reports an entry that would be created on a read-only
+// filesystem. Paths are relative to the destination directory.
+type ReadOnlyError struct {
+	// Path is the path of the entry.
+	Path string
+	// MountPoint is where the read-only filesystem is mounted.
+	MountPoint string
+	// Alternative is a suggested writable path, or "" if none is known.
+	Alternative string
+}
+
+func (e ReadOnlyError) Error() string {
+	msg := fmt.Sprintf("cannot write %q: the filesystem mounted at %q is read-only", e.Path, e.MountPoint)
+	if e.Alternative != "" {
+		return fmt.Sprintf("%s; consider writing %q instead", msg, e.Alternative)
+	}
+	return msg + "; consider a path under /etc or /var instead"
+}
+
+// WritableAlternative returns the conventional writable location
+// overriding path, or "" if there isn't one.
+func WritableAlternative(path string) string {
+	for _, a := range writableAlternatives {
+		if strings.HasPrefix(path, a.prefix) {
+			return a.alternative + strings.TrimPrefix(path, a.prefix)
+		}
+	}
+	return ""
+}
+
+// CheckWritable returns a ReadOnlyError if the directory in which path
+// would be created is on a read-only filesystem, so that such entries can
+// be reported before anything is written. path must be under u.DestDir.
+func (u Util) CheckWritable(path string) error {
+	missing, err := u.FindFirstMissingPathComponent(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(missing)
+	readOnly, err := u.readOnly(dir)
+	if err != nil {
+		return err
+	}
+	if !readOnly {
+		return nil
+	}
+	mountPoint, err := u.mountPoint(dir)
+	if err != nil {
+		return err
+	}
+	relPath := u.relativeToDestDir(path)
+	return ReadOnlyError{
+		Path:        relPath,
+		MountPoint:  u.relativeToDestDir(mountPoint),
+		Alternative: WritableAlternative(relPath),
+	}
+}
+
+// mountPoint returns the topmost directory above the read-only dir, but not
+// above u.DestDir, that is on the same device as dir and also read-only.
+// Read-only bind mounts, such as /usr on OSTree systems, are on the same
+// device as their parent directory.
+func (u Util) mountPoint(dir string) (string, error) {
+	dev, err := u.deviceOf(dir)
+	if err != nil {
+		return "", err
+	}
+	root := filepath.Join("/", u.DestDir)
+	for dir != root && dir != "/" {
+		parent := filepath.Dir(dir)
+		parentDev, err := u.deviceOf(parent)
+		if err != nil {
+			return "", err
+		}
+		if parentDev != dev {
+			break
+		}
+		readOnly, err := u.readOnly(parent)
+		if err != nil {
+			return "", err
+		}
+		if !readOnly {
+			break
+		}
+		dir = parent
+	}
+	return dir, nil
+}
+
+func (u Util) readOnly(dir string) (bool, error) {
+	var st unix.Statfs_t
+	if err := u.FS().Statfs(dir, &st); err != nil {
+		return false, fmt.Errorf("checking filesystem of %q: %w", dir, err)
+	}
+	return st.Flags&unix.ST_RDONLY != 0, nil
+}
+
+func (u Util) relativeToDestDir(path string) string {
+	return filepath.Join("/", strings.TrimPrefix(path, u.DestDir))
+}
+
+func (u Util) deviceOf(path string) (uint64, error) {
+	info, err := u.FS().Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("couldn't get device of %q", path)
+	}
+	return uint64(st.Dev), nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestWritableAlternative(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"/usr/lib/systemd/system/foo.service", "/etc/systemd/system/foo.service"},
+		{"/usr/lib/systemd/system/foo.service.d/10-bar.conf", "/etc/systemd/system/foo.service.d/10-bar.conf"},
+		{"/usr/lib/systemd/network/10-foo.network", "/etc/systemd/network/10-foo.network"},
+		{"/usr/lib/systemd/journald.conf.d/foo.conf", "/etc/systemd/journald.conf.d/foo.conf"},
+		{"/usr/lib/systemd/systemd-foo", ""},
+		{"/usr/lib/systemd/system-generators/foo", ""},
+		{"/usr/lib/sysctl.d/foo.conf", "/etc/sysctl.d/foo.conf"},
+		{"/usr/lib/tmpfiles.d/foo.conf", "/etc/tmpfiles.d/foo.conf"},
+		{"/usr/lib/udev/rules.d/foo.rules", "/etc/udev/rules.d/foo.rules"},
+		{"/usr/lib/udev/foo", ""},
+		{"/usr/lib/os-release", ""},
+		{"/usr/local/bin/foo", "/var/usrlocal/bin/foo"},
+		{"/opt/foo/bar", "/var/opt/foo/bar"},
+		{"/usr/bin/foo", ""},
+		{"/usr/library", ""},
+	}
+
+	for i, test := range tests {
+		if out := WritableAlternative(test.in); out != test.out {
+			t.Errorf("#%d: expected %q, got %q", i, test.out, out)
+		}
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	tests := []struct {
+		path string
+		err  error
+	}{
+		{"/sysroot/etc/foo", nil},
+		{"/sysroot/var/missing/dirs/file", nil},
+		{
+			"/sysroot/usr/lib/systemd/system/foo.service",
+			ReadOnlyError{
+				Path:        "/usr/lib/systemd/system/foo.service",
+				MountPoint:  "/usr",
+				Alternative: "/etc/systemd/system/foo.service",
+			},
+		},
+		{
+			"/sysroot/usr/missing/file",
+			ReadOnlyError{
+				Path:       "/usr/missing/file",
+				MountPoint: "/usr",
+			},
+		},
+		{
+			// separately mounted filesystem below /usr
+			"/sysroot/usr/local/bin/foo",
+			ReadOnlyError{
+				Path:        "/usr/local/bin/foo",
+				MountPoint:  "/usr/local",
+				Alternative: "/var/usrlocal/bin/foo",
+			},
+		},
+	}
+
+	fs := NewMemFS()
+	for _, dir := range []string{"/sysroot/etc", "/sysroot/var", "/sysroot/usr/lib/systemd/system", "/sysroot/usr/local/bin"} {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs.MountReadOnly("/sysroot/usr")
+	fs.MountReadOnly("/sysroot/usr/local")
+	u := Util{DestDir: "/sysroot", FileSystem: fs}
+	for i, test := range tests {
+		if err := u.CheckWritable(test.path); !reflect.DeepEqual(err, test.err) {
+			t.Errorf("#%d: expected error %v, got %v", i, test.err, err)
+		}
+	}
+}
+
+func TestCheckWritableReadOnlyMount(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("bind mounting requires root")
+	}
+	dir := t.TempDir()
+	ro := filepath.Join(dir, "ro")
+	if err := os.Mkdir(ro, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Mount(ro, ro, "", unix.MS_BIND, ""); err != nil {
+		t.Skipf("bind mounting: %v", err)
+	}
+	defer func() {
+		if err := unix.Unmount(ro, 0); err != nil {
+			t.Errorf("unmounting: %v", err)
+		}
+	}()
+	if err := unix.Mount("", ro, "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY, ""); err != nil {
+		t.Fatalf("remounting read-only: %v", err)
+	}
+
+	u := Util{DestDir: dir}
+	if err := u.CheckWritable(filepath.Join(dir, "missing/dirs/file")); err != nil {
+		t.Errorf("writable directory reported as read-only: %v", err)
+	}
+	expected := ReadOnlyError{
+		Path:       "/ro/missing/file",
+		MountPoint: "/ro",
+	}
+	if err := u.CheckWritable(filepath.Join(ro, "missing/file")); !reflect.DeepEqual(err, expected) {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+}
+
+func TestReadOnlyError(t *testing.T) {
+	err := ReadOnlyError{
+		Path:        "/usr/lib/foo",
+		MountPoint:  "/usr",
+		Alternative: "/etc/foo",
+	}
+	expected := `cannot write "/usr/lib/foo": the filesystem mounted at "/usr" is read-only; consider writing "/etc/foo" instead`
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
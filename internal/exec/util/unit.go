@@ -87,13 +87,13 @@ func (ut Util) MaskUnit(unit types.Unit) (string, error) {
 		return "", err
 	}
 
-	if err := MkdirForFile(path); err != nil {
+	if err := ut.FS().MkdirAll(filepath.Dir(path), DefaultDirectoryPermissions); err != nil {
 		return "", err
 	}
-	if err := os.RemoveAll(path); err != nil {
+	if err := ut.FS().RemoveAll(path); err != nil {
 		return "", err
 	}
-	if err := os.Symlink("/dev/null", path); err != nil {
+	if err := ut.FS().Symlink("/dev/null", path); err != nil {
 		return "", err
 	}
 	// not the same as the path above, since this lacks the sysroot prefix
@@ -113,7 +113,7 @@ func (ut Util) UnmaskUnit(unit types.Unit) error {
 	}
 	// If masked, remove the symlink
 	if masked {
-		if err = os.Remove(path); err != nil {
+		if err = ut.FS().Remove(path); err != nil {
 			return err
 		}
 	}
@@ -127,7 +127,7 @@ func (ut Util) IsUnitMasked(unit types.Unit) (bool, error) {
 		return false, err
 	}
 
-	target, err := os.Readlink(path)
+	target, err := ut.FS().Readlink(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// The path doesn't exist, hence the unit isn't masked
@@ -185,7 +185,7 @@ func (ut Util) appendLineToPreset(data string) error {
 		return err
 	}
 
-	if err := MkdirForFile(path); err != nil {
+	if err := ut.MkdirForFile(path); err != nil {
 		return err
 	}
 	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, DefaultPresetPermissions)
@@ -29,6 +29,8 @@ type Util struct {
 	Fetcher resource.Fetcher
 	*log.Logger
 	State *state.State
+	// FileSystem overrides the FS returned by FS, for tests.
+	FileSystem FS
 }
 
 // SplitPath splits /a/b/c/d into [a, b, c, d]
@@ -48,12 +50,12 @@ func SplitPath(p string) []string {
 // to the target.
 func (u Util) ResolveSymlink(path string) (string, error) {
 	prefixedPath := filepath.Join(u.DestDir, path)
-	s, err := os.Lstat(prefixedPath)
+	s, err := u.FS().Lstat(prefixedPath)
 	if err != nil || s.Mode()&os.ModeSymlink == 0 {
 		return "", err
 	}
 
-	symlinkPath, err := os.Readlink(prefixedPath)
+	symlinkPath, err := u.FS().Readlink(prefixedPath)
 	if err != nil {
 		return "", err
 	}
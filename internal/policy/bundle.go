@@ -0,0 +1,135 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/coreos/vcontext/validate"
+)
+
+var (
+	ErrSchemeNotAllowed   = errors.New("URL scheme is not allowed by site policy")
+	ErrPasswordHashDenied = errors.New("password hashes are not allowed by site policy")
+	ErrPathDenied         = errors.New("path is not allowed by site policy")
+	ErrKernelArgsDenied   = errors.New("kernel arguments are not allowed by site policy")
+)
+
+// Bundle is a declarative site policy, read from a JSON file in the system
+// config directory. Unset rules aren't enforced.
+type Bundle struct {
+	// AllowedSchemes lists the URL schemes that resources may be
+	// fetched from, e.g. ["https", "data"].
+	AllowedSchemes []string `json:"allowedSchemes,omitempty"`
+	// DenyPasswordHash forbids setting password hashes for users,
+	// leaving SSH keys as the only way to log in.
+	DenyPasswordHash bool `json:"denyPasswordHash,omitempty"`
+	// DeniedPaths lists absolute paths that files, directories, and links
+	// may not be created at or below.
+	DeniedPaths []string `json:"deniedPaths,omitempty"`
+	// DenyKernelArguments forbids changing kernel arguments.
+	DenyKernelArguments bool `json:"denyKernelArguments,omitempty"`
+}
+
+// LoadBundle reads the site policy bundle at path. It returns nil if there
+// is no bundle.
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var bundle Bundle
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// a typo shouldn't silently disable a rule
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", path, err)
+	}
+	return &bundle, nil
+}
+
+func (Bundle) Name() string {
+	return "site policy bundle"
+}
+
+func (b Bundle) Evaluate(config types.Config) (r report.Report) {
+	if len(b.AllowedSchemes) > 0 {
+		r.Merge(validate.ValidateCustom(config, "json", b.checkScheme))
+	}
+	for i, user := range config.Passwd.Users {
+		if b.DenyPasswordHash && cutil.NotEmpty(user.PasswordHash) {
+			r.AddOnError(path.New("json", "passwd", "users", i, "passwordHash"), ErrPasswordHashDenied)
+		}
+	}
+	for _, denied := range b.DeniedPaths {
+		for i, f := range config.Storage.Files {
+			r.AddOnError(path.New("json", "storage", "files", i, "path"), checkPath(f.Path, denied))
+		}
+		for i, d := range config.Storage.Directories {
+			r.AddOnError(path.New("json", "storage", "directories", i, "path"), checkPath(d.Path, denied))
+		}
+		for i, l := range config.Storage.Links {
+			r.AddOnError(path.New("json", "storage", "links", i, "path"), checkPath(l.Path, denied))
+		}
+	}
+	if b.DenyKernelArguments && (len(config.KernelArguments.ShouldExist) > 0 || len(config.KernelArguments.ShouldNotExist) > 0) {
+		r.AddOnError(path.New("json", "kernelArguments"), ErrKernelArgsDenied)
+	}
+	return
+}
+
+// checkScheme reports resources whose source uses a scheme not in
+// AllowedSchemes.
+func (b Bundle) checkScheme(v reflect.Value, c path.ContextPath) (r report.Report) {
+	resource, ok := v.Interface().(types.Resource)
+	if !ok || cutil.NilOrEmpty(resource.Source) {
+		return
+	}
+	u, err := url.Parse(*resource.Source)
+	if err != nil {
+		// already reported by validation
+		return
+	}
+	for _, scheme := range b.AllowedSchemes {
+		if u.Scheme == scheme {
+			return
+		}
+	}
+	r.AddOnError(c.Append("source"), fmt.Errorf("%w: %q", ErrSchemeNotAllowed, u.Scheme))
+	return
+}
+
+func checkPath(p, denied string) error {
+	rel, err := filepath.Rel(filepath.Clean(denied), filepath.Clean(p))
+	if err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+		return fmt.Errorf("%w: %q is under %q", ErrPathDenied, p, denied)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundleEvaluate(t *testing.T) {
+	tests := []struct {
+		bundle Bundle
+		in     types.Config
+		out    report.Report
+	}{
+		// no rules
+		{
+			in: types.Config{
+				Passwd: types.Passwd{
+					Users: []types.PasswdUser{{Name: "core", PasswordHash: util.StrToPtr("$6$x")}},
+				},
+			},
+		},
+		{
+			bundle: Bundle{AllowedSchemes: []string{"https", "data"}},
+			in: types.Config{
+				Storage: types.Storage{
+					Files: []types.File{
+						{
+							Node: types.Node{Path: "/a"},
+							FileEmbedded1: types.FileEmbedded1{
+								Contents: types.Resource{Source: util.StrToPtr("https://example.com/a")},
+							},
+						},
+						{
+							Node: types.Node{Path: "/b"},
+							FileEmbedded1: types.FileEmbedded1{
+								Append: []types.Resource{{Source: util.StrToPtr("http://example.com/b")}},
+							},
+						},
+					},
+				},
+			},
+			out: func() (r report.Report) {
+				r.AddOnError(path.New("json", "storage", "files", 1, "append", 0, "source"), fmt.Errorf("%w: %q", ErrSchemeNotAllowed, "http"))
+				return
+			}(),
+		},
+		{
+			bundle: Bundle{DenyPasswordHash: true},
+			in: types.Config{
+				Passwd: types.Passwd{
+					Users: []types.PasswdUser{
+						{Name: "core"},
+						{Name: "admin", PasswordHash: util.StrToPtr("$6$x")},
+					},
+				},
+			},
+			out: func() (r report.Report) {
+				r.AddOnError(path.New("json", "passwd", "users", 1, "passwordHash"), ErrPasswordHashDenied)
+				return
+			}(),
+		},
+		{
+			bundle: Bundle{DeniedPaths: []string{"/etc/ssh"}},
+			in: types.Config{
+				Storage: types.Storage{
+					Files:       []types.File{{Node: types.Node{Path: "/etc/sshd"}}},
+					Directories: []types.Directory{{Node: types.Node{Path: "/etc/ssh"}}},
+					Links:       []types.Link{{Node: types.Node{Path: "/etc/ssh/sshd_config"}}},
+				},
+			},
+			out: func() (r report.Report) {
+				r.AddOnError(path.New("json", "storage", "directories", 0, "path"), checkPath("/etc/ssh", "/etc/ssh"))
+				r.AddOnError(path.New("json", "storage", "links", 0, "path"), checkPath("/etc/ssh/sshd_config", "/etc/ssh"))
+				return
+			}(),
+		},
+		{
+			bundle: Bundle{DenyKernelArguments: true},
+			in: types.Config{
+				KernelArguments: types.KernelArguments{
+					ShouldExist: []types.KernelArgument{"quiet"},
+				},
+			},
+			out: func() (r report.Report) {
+				r.AddOnError(path.New("json", "kernelArguments"), ErrKernelArgsDenied)
+				return
+			}(),
+		},
+	}
+
+	for i, test := range tests {
+		r := test.bundle.Evaluate(test.in)
+		assert.Equal(t, test.out, r, "#%d: bad report", i)
+	}
+}
+
+func TestLoadBundle(t *testing.T) {
+	dir := t.TempDir()
+
+	bundle, err := LoadBundle(filepath.Join(dir, "missing.json"))
+	assert.NoError(t, err)
+	assert.Nil(t, bundle)
+
+	valid := filepath.Join(dir, "valid.json")
+	assert.NoError(t, os.WriteFile(valid, []byte(`{"allowedSchemes": ["https"], "denyPasswordHash": true}`), 0644))
+	bundle, err = LoadBundle(valid)
+	assert.NoError(t, err)
+	assert.Equal(t, &Bundle{AllowedSchemes: []string{"https"}, DenyPasswordHash: true}, bundle)
+
+	typo := filepath.Join(dir, "typo.json")
+	assert.NoError(t, os.WriteFile(typo, []byte(`{"allowedScheme": ["https"]}`), 0644))
+	_, err = LoadBundle(typo)
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The policy package evaluates the merged config against site rules before
+// each stage runs, so that configs violating them fail before anything is
+// changed.
+package policy
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
+	"github.com/coreos/ignition/v2/internal/distro"
+	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/registry"
+
+	"github.com/coreos/vcontext/report"
+)
+
+const (
+	// bundleFilename is the name of the site policy bundle in the system
+	// config directory
+	bundleFilename = "policy.json"
+)
+
+var (
+	ErrPolicyViolation = errors.New("config violates site policy")
+)
+
+// Policy is a set of site rules a config must satisfy.
+type Policy interface {
+	Name() string
+	// Evaluate returns a report with an error for every rule config
+	// violates. Warnings are logged but don't fail the stage.
+	Evaluate(config types.Config) report.Report
+}
+
+var policies = registry.Create("policies")
+
+// Register registers a policy to be evaluated before every stage, in
+// addition to the site policy bundle.
+func Register(policy Policy) {
+	policies.Register(policy)
+}
+
+// Check evaluates config against the registered policies and the site policy
+// bundle, if there is one, logging any violations.
+func Check(logger *log.Logger, config types.Config) error {
+	all := []Policy{}
+	for _, name := range policies.Names() {
+		all = append(all, policies.Get(name).(Policy))
+	}
+	bundle, err := LoadBundle(filepath.Join(distro.SystemConfigDir(), bundleFilename))
+	if err != nil {
+		logger.Crit("couldn't load site policy bundle: %v", err)
+		return err
+	}
+	if bundle != nil {
+		all = append(all, bundle)
+	}
+
+	violated := false
+	for _, policy := range all {
+		r := policy.Evaluate(config)
+		for _, entry := range r.Entries {
+			if entry.Kind.IsFatal() {
+				logger.Crit("%s: %s", policy.Name(), entry)
+			} else {
+				logger.Warning("%s: %s", policy.Name(), entry)
+			}
+		}
+		violated = violated || r.IsFatal()
+	}
+	if violated {
+		return ErrPolicyViolation
+	}
+	return nil
+}
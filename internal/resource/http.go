@@ -280,15 +280,16 @@ func shouldRetryHttp(statusCode int, opts FetchOptions) bool {
 
 // httpReaderWithHeader performs an HTTP request on the provided URL with the
 // provided request header & method and returns the response body Reader, HTTP
-// status code, a cancel function for the result's context, and error (if any).
+// status code, content length (-1 if unknown), a cancel function for the
+// result's context, and error (if any).
 // By default, User-Agent is added to the header but this can be overridden.
-func (c HttpClient) httpReaderWithHeader(opts FetchOptions, url string) (io.ReadCloser, int, context.CancelFunc, error) {
+func (c HttpClient) httpReaderWithHeader(opts FetchOptions, url string) (io.ReadCloser, int, int64, context.CancelFunc, error) {
 	if opts.HTTPVerb == "" {
 		opts.HTTPVerb = "GET"
 	}
 	req, err := http.NewRequest(opts.HTTPVerb, url, nil)
 	if err != nil {
-		return nil, 0, nil, err
+		return nil, 0, 0, nil, err
 	}
 
 	req.Header.Set("User-Agent", "Ignition/"+version.Raw)
@@ -314,7 +315,7 @@ func (c HttpClient) httpReaderWithHeader(opts FetchOptions, url string) (io.Read
 		if err == nil {
 			c.logger.Info("%s result: %s", opts.HTTPVerb, http.StatusText(resp.StatusCode))
 			if !shouldRetryHttp(resp.StatusCode, opts) {
-				return resp.Body, resp.StatusCode, cancelFn, nil
+				return resp.Body, resp.StatusCode, resp.ContentLength, cancelFn, nil
 			}
 			resp.Body.Close()
 		} else {
@@ -325,7 +326,7 @@ func (c HttpClient) httpReaderWithHeader(opts FetchOptions, url string) (io.Read
 		select {
 		case <-time.After(duration):
 		case <-ctx.Done():
-			return nil, 0, cancelFn, ErrTimeout
+			return nil, 0, 0, cancelFn, ErrTimeout
 		}
 
 		duration = duration * 2
@@ -57,6 +57,8 @@ var (
 	ErrFailed                 = errors.New("failed to fetch resource")
 	ErrCompressionUnsupported = errors.New("compression is not supported with that scheme")
 	ErrNeedNet                = errors.New("resource requires networking")
+	ErrSizeUnknown            = errors.New("size of resource can't be determined before fetching it")
+	ErrTooLarge               = errors.New("resource is larger than the maximum size")
 
 	// ConfigHeaders are the HTTP headers that should be used when the Ignition
 	// config is being fetched
@@ -135,6 +137,13 @@ type FetchOptions struct {
 	// List of HTTP codes to retry that usually would be considered as complete.
 	// Status codes >= 500 are always retried.
 	RetryCodes []int
+
+	// MaxSize, if nonzero, is the largest accepted size of the resource
+	// after decompression. The size is checked before anything is written
+	// to the destination, so resources whose size isn't known up front
+	// fail with ErrSizeUnknown: only data URLs and uncompressed HTTP(S)
+	// resources with a Content-Length are supported.
+	MaxSize int64
 }
 
 // FetchToBuffer will fetch the given url into a temporary file, and then read
@@ -208,6 +217,22 @@ func (f *Fetcher) Fetch(u url.URL, dest *os.File, opts FetchOptions) error {
 		return ErrNeedNet
 	}
 
+	switch u.Scheme {
+	case "s3", "arn":
+		return f.fetchFromS3(u, dest, opts)
+	default:
+		return f.FetchToWriter(u, dest, opts)
+	}
+}
+
+// FetchToWriter is like Fetch, but streams the results into an arbitrary
+// io.Writer. S3 URLs aren't supported, since S3 objects are downloaded in
+// chunks out of order.
+func (f *Fetcher) FetchToWriter(u url.URL, dest io.Writer, opts FetchOptions) error {
+	if f.Offline && util.UrlNeedsNet(u) {
+		return ErrNeedNet
+	}
+
 	switch u.Scheme {
 	case "http", "https":
 		return f.fetchFromHTTP(u, dest, opts)
@@ -215,8 +240,6 @@ func (f *Fetcher) Fetch(u url.URL, dest *os.File, opts FetchOptions) error {
 		return f.fetchFromTFTP(u, dest, opts)
 	case "data":
 		return f.fetchFromDataURL(u, dest, opts)
-	case "s3", "arn":
-		return f.fetchFromS3(u, dest, opts)
 	case "gs":
 		return f.fetchFromGCS(u, dest, opts)
 	case "":
@@ -229,6 +252,9 @@ func (f *Fetcher) Fetch(u url.URL, dest *os.File, opts FetchOptions) error {
 // FetchFromTFTP fetches a resource from u via TFTP into dest, returning an
 // error if one is encountered.
 func (f *Fetcher) fetchFromTFTP(u url.URL, dest io.Writer, opts FetchOptions) error {
+	if err := checkSize(-1, opts); err != nil {
+		return err
+	}
 	if !strings.ContainsRune(u.Host, ':') {
 		u.Host = u.Host + ":69"
 	}
@@ -341,7 +367,7 @@ func (f *Fetcher) fetchFromHTTP(u url.URL, dest io.Writer, opts FetchOptions) er
 
 	requestOpts := opts
 	requestOpts.Headers = headers
-	dataReader, status, ctxCancel, err := f.client.httpReaderWithHeader(requestOpts, u.String())
+	dataReader, status, contentLength, ctxCancel, err := f.client.httpReaderWithHeader(requestOpts, u.String())
 	if ctxCancel != nil {
 		// whatever context getReaderWithHeader created for the request should
 		// be cancelled once we're done reading the response
@@ -361,6 +387,14 @@ func (f *Fetcher) fetchFromHTTP(u url.URL, dest io.Writer, opts FetchOptions) er
 		return ErrFailed
 	}
 
+	if opts.Compression != "" {
+		// Content-Length is the compressed size
+		contentLength = -1
+	}
+	if err := checkSize(contentLength, opts); err != nil {
+		return err
+	}
+
 	return f.decompressCopyHashAndVerify(dest, dataReader, opts)
 }
 
@@ -372,6 +406,23 @@ func (f *Fetcher) fetchFromDataURL(u url.URL, dest io.Writer, opts FetchOptions)
 		return err
 	}
 
+	if opts.MaxSize != 0 {
+		size := int64(len(url.Data))
+		if opts.Compression != "" {
+			// the data is in memory anyway, so just decompress it twice
+			sizeOpts := opts
+			sizeOpts.Hash = nil
+			counter := &countingWriter{}
+			if err := f.decompressCopyHashAndVerify(counter, bytes.NewBuffer(url.Data), sizeOpts); err != nil {
+				return err
+			}
+			size = counter.n
+		}
+		if err := checkSize(size, opts); err != nil {
+			return err
+		}
+	}
+
 	return f.decompressCopyHashAndVerify(dest, bytes.NewBuffer(url.Data), opts)
 }
 
@@ -380,6 +431,9 @@ func (f *Fetcher) fetchFromDataURL(u url.URL, dest io.Writer, opts FetchOptions)
 // server on GCE. If it fails to get the credentials, then it will fall back to anonymous
 // credentials to fetch the object content.
 func (f *Fetcher) fetchFromGCS(u url.URL, dest io.Writer, opts FetchOptions) error {
+	if err := checkSize(-1, opts); err != nil {
+		return err
+	}
 	ctx := context.Background()
 	if f.GCSSession == nil {
 		clientOption := option.WithoutAuthentication()
@@ -431,6 +485,9 @@ func (f *Fetcher) fetchFromS3(u url.URL, dest s3target, opts FetchOptions) error
 	if opts.Compression != "" {
 		return ErrCompressionUnsupported
 	}
+	if err := checkSize(-1, opts); err != nil {
+		return err
+	}
 	ctx := context.Background()
 	if f.client != nil && f.client.timeout != 0 {
 		var cancelFn context.CancelFunc
@@ -598,6 +655,29 @@ func (f *Fetcher) decompressCopyHashAndVerify(dest io.Writer, src io.Reader, opt
 	return nil
 }
 
+// checkSize returns an error if a resource of size bytes, or of unknown
+// size if size is negative, exceeds opts.MaxSize.
+func checkSize(size int64, opts FetchOptions) error {
+	switch {
+	case opts.MaxSize == 0:
+		return nil
+	case size < 0:
+		return ErrSizeUnknown
+	case size > opts.MaxSize:
+		return ErrTooLarge
+	}
+	return nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
 // parseARN is a custom wrapper around arn.Parse(); it takes arnURL, a full ARN URL,
 // and returns a bucket, a key, a potentially empty region, and a
 // potentially empty region hint for use in region detection; or an error if
@@ -619,6 +699,9 @@ func (f *Fetcher) parseARN(arnURL string) (string, string, string, string, error
 	regionHint := awsPartitionRegionHints[s3arn.Partition]
 	// Split the ARN bucket (or accesspoint) and key by separating on slashes.
 	// See https://docs.aws.amazon.com/general/latest/gr/aws-arns-and-namespaces.html#arns-paths for more info.
+	// The key is percent-decoded so that it matches the key of the
+	// equivalent s3:// URL. Configs are translated to the current spec
+	// before fetching, so this applies to all spec versions.
 	urlSplit := strings.Split(arnURL, "/")
 
 	// Determine if the ARN is for an access point or a bucket.
@@ -634,7 +717,10 @@ func (f *Fetcher) parseARN(arnURL string) (string, string, string, string, error
 		// For more information about access point ARNs, see Using access points
 		// https://docs.aws.amazon.com/AmazonS3/latest/userguide/using-access-points.html
 		bucket := strings.Join(urlSplit[:2], "/")
-		key := strings.Join(urlSplit[3:], "/")
+		key, err := url.PathUnescape(strings.Join(urlSplit[3:], "/"))
+		if err != nil {
+			return "", "", "", "", configErrors.ErrInvalidUrlEscape
+		}
 		return bucket, key, s3arn.Region, regionHint, nil
 	}
 	// urlSplit must consist of name of bucket and key
@@ -647,6 +733,9 @@ func (f *Fetcher) parseARN(arnURL string) (string, string, string, string, error
 	// https://docs.aws.amazon.com/AmazonS3/latest/userguide/s3-arn-format.html
 	bucketUrlSplit := strings.Split(urlSplit[0], ":")
 	bucket := bucketUrlSplit[len(bucketUrlSplit)-1]
-	key := strings.Join(urlSplit[1:], "/")
+	key, err := url.PathUnescape(strings.Join(urlSplit[1:], "/"))
+	if err != nil {
+		return "", "", "", "", configErrors.ErrInvalidUrlEscape
+	}
 	return bucket, key, "", regionHint, nil
 }
@@ -16,12 +16,24 @@ package resource
 
 import (
 	"compress/gzip"
+	"context"
 	"crypto/sha512"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pin/tftp"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
 	"github.com/coreos/ignition/v2/internal/log"
@@ -91,6 +103,50 @@ func TestDataUrl(t *testing.T) {
 				Expected:   "807e8ff949e61d23f5ee42a629ec96e9fc526b62f030cd70ba2cd5b9d97935461eacc29bf58bcd0426e9e1fdb0eda939603ed52c9c06d0712208a15cd582c600",
 			}},
 		},
+		// data url, within maximum size
+		{
+			in: in{
+				url: "data:,hello%20world%0a",
+				opts: FetchOptions{
+					MaxSize:     12,
+					ExpectedSum: []byte("\xdb\x39\x74\xa9\x7f\x24\x07\xb7\xca\xe1\xae\x63\x7c\x00\x30\x68\x7a\x11\x91\x32\x74\xd5\x78\x49\x25\x58\xe3\x9c\x16\xc0\x17\xde\x84\xea\xcd\xc8\xc6\x2f\xe3\x4e\xe4\xe1\x2b\x4b\x14\x28\x81\x7f\x09\xb6\xa2\x76\x0c\x3f\x8a\x66\x4c\xea\xe9\x4d\x24\x34\xa5\x93"),
+				},
+			},
+			out: out{data: []byte("hello world\n")},
+		},
+		// data url, larger than maximum size
+		{
+			in: in{
+				url: "data:,hello%20world%0a",
+				opts: FetchOptions{
+					MaxSize: 11,
+				},
+			},
+			out: out{err: ErrTooLarge},
+		},
+		// data url, gzipped, decompressed size within maximum size
+		{
+			in: in{
+				url: "data:,%1F%8B%08%08%90e%AB%5E%02%03z%00K%ADH%CC-%C8IUH%CB%CCI%E5%02%00tp%A6%CB%0D%00%00%00",
+				opts: FetchOptions{
+					Compression: "gzip",
+					MaxSize:     13,
+					ExpectedSum: []byte("\x80\x7e\x8f\xf9\x49\xe6\x1d\x23\xf5\xee\x42\xa6\x29\xec\x96\xe9\xfc\x52\x6b\x62\xf0\x30\xcd\x70\xba\x2c\xd5\xb9\xd9\x79\x35\x46\x1e\xac\xc2\x9b\xf5\x8b\xcd\x04\x26\xe9\xe1\xfd\xb0\xed\xa9\x39\x60\x3e\xd5\x2c\x9c\x06\xd0\x71\x22\x08\xa1\x5c\xd5\x82\xc6\x0e"),
+				},
+			},
+			out: out{data: []byte("example file\n")},
+		},
+		// data url, gzipped, decompressed size larger than maximum size
+		{
+			in: in{
+				url: "data:,%1F%8B%08%08%90e%AB%5E%02%03z%00K%ADH%CC-%C8IUH%CB%CCI%E5%02%00tp%A6%CB%0D%00%00%00",
+				opts: FetchOptions{
+					Compression: "gzip",
+					MaxSize:     12,
+				},
+			},
+			out: out{err: ErrTooLarge},
+		},
 		// data url, invalid compressed data
 		{
 			in: in{
@@ -322,6 +378,23 @@ func TestParseARN(t *testing.T) {
 			region:     "us-west-2",
 			regionHint: "us-east-1",
 		},
+		{
+			url:        "arn:aws:s3:::kola-fixtures/path/a%20file+with%2Bplus",
+			bucket:     "kola-fixtures",
+			key:        "path/a file+with+plus",
+			regionHint: "us-east-1",
+		},
+		{
+			url:        "arn:aws:s3:us-west-2:123456789012:accesspoint/test/object/a%2Fb+c",
+			bucket:     "arn:aws:s3:us-west-2:123456789012:accesspoint/test",
+			key:        "a/b+c",
+			region:     "us-west-2",
+			regionHint: "us-east-1",
+		},
+		{
+			url: "arn:aws:s3:::kola-fixtures/a%zz",
+			err: errors.ErrInvalidUrlEscape,
+		},
 	}
 
 	logger := log.New(true)
@@ -338,3 +411,221 @@ func TestParseARN(t *testing.T) {
 		assert.Equal(t, test.regionHint, regionHint, "#%d: bad region hint", i)
 	}
 }
+
+func TestFetchHTTPEncodedPath(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.EscapedPath())
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path string
+		out  string
+	}{
+		{"/a%20file", "/a%20file"},
+		{"/a+file", "/a+file"},
+		{"/a%2Bfile", "/a%2Bfile"},
+		{"/dir%2Ffile", "/dir%2Ffile"},
+	}
+
+	logger := log.New(true)
+	f := Fetcher{
+		Logger: &logger,
+	}
+	for i, test := range tests {
+		u, err := url.Parse(server.URL + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requested = nil
+		if _, err := f.FetchToBuffer(*u, FetchOptions{}); err != nil {
+			t.Errorf("#%d: fetching: %v", i, err)
+			continue
+		}
+		if len(requested) != 1 || requested[0] != test.out {
+			t.Errorf("#%d: expected request for %q, got %v", i, test.out, requested)
+		}
+	}
+}
+
+func TestFetchHTTPMaxSize(t *testing.T) {
+	var chunked bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if chunked {
+			// flushing before writing omits Content-Length
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte("hello world\n"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		chunked bool
+		opts    FetchOptions
+		err     error
+	}{
+		{false, FetchOptions{}, nil},
+		{false, FetchOptions{MaxSize: 12}, nil},
+		{false, FetchOptions{MaxSize: 11}, ErrTooLarge},
+		{false, FetchOptions{MaxSize: 12, Compression: "gzip"}, ErrSizeUnknown},
+		{true, FetchOptions{}, nil},
+		{true, FetchOptions{MaxSize: 12}, ErrSizeUnknown},
+	}
+
+	logger := log.New(true)
+	f := Fetcher{
+		Logger: &logger,
+	}
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, test := range tests {
+		chunked = test.chunked
+		_, err := f.FetchToBuffer(*u, test.opts)
+		if err != test.err {
+			t.Errorf("#%d: expected error %v, got %v", i, test.err, err)
+		}
+	}
+}
+
+func TestFetchS3EncodedKey(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			// bucket region lookup
+			w.Header().Set("X-Amz-Bucket-Region", "us-east-1")
+			return
+		}
+		requested = append(requested, r.URL.Path)
+		_, _ = w.Write([]byte("hello world\n"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		url string
+		key string
+	}{
+		{"s3://bucket/a%20file", "/bucket/a file"},
+		{"s3://bucket/a+file", "/bucket/a+file"},
+		{"s3://bucket/a%2Bfile", "/bucket/a+file"},
+		{"arn:aws:s3:::bucket/a%20file", "/bucket/a file"},
+		{"arn:aws:s3:::bucket/a+file", "/bucket/a+file"},
+		{"arn:aws:s3:::bucket/a%2Bfile", "/bucket/a+file"},
+	}
+
+	logger := log.New(true)
+	for i, test := range tests {
+		sess, err := session.NewSession(&aws.Config{
+			Credentials:      credentials.AnonymousCredentials,
+			Endpoint:         aws.String(server.URL),
+			S3ForcePathStyle: aws.Bool(true),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := Fetcher{
+			Logger:     &logger,
+			AWSSession: sess,
+		}
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requested = nil
+		if _, err := f.FetchToBuffer(*u, FetchOptions{}); err != nil {
+			t.Errorf("#%d: fetching: %v", i, err)
+			continue
+		}
+		if len(requested) != 1 || requested[0] != test.key {
+			t.Errorf("#%d: expected request for %q, got %v", i, test.key, requested)
+		}
+	}
+}
+
+func TestFetchGCSEncodedPath(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		_, _ = w.Write([]byte("hello world\n"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		url    string
+		object string
+	}{
+		{"gs://bucket/a%20file", "/bucket/a file"},
+		{"gs://bucket/a+file", "/bucket/a+file"},
+		{"gs://bucket/a%2Bfile", "/bucket/a+file"},
+	}
+
+	logger := log.New(true)
+	client, err := storage.NewClient(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	f := Fetcher{
+		Logger:     &logger,
+		GCSSession: client,
+	}
+	for i, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requested = nil
+		if _, err := f.FetchToBuffer(*u, FetchOptions{}); err != nil {
+			t.Errorf("#%d: fetching: %v", i, err)
+			continue
+		}
+		if len(requested) != 1 || requested[0] != test.object {
+			t.Errorf("#%d: expected request for %q, got %v", i, test.object, requested)
+		}
+	}
+}
+
+func TestFetchTFTPEncodedPath(t *testing.T) {
+	var requested []string
+	server := tftp.NewServer(func(filename string, rf io.ReaderFrom) error {
+		requested = append(requested, filename)
+		_, err := rf.ReadFrom(strings.NewReader("hello world\n"))
+		return err
+	}, nil)
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(conn)
+	defer server.Shutdown()
+
+	tests := []struct {
+		path     string
+		filename string
+	}{
+		{"/a%20file", "/a file"},
+		{"/a+file", "/a+file"},
+		{"/a%2Bfile", "/a+file"},
+	}
+
+	logger := log.New(true)
+	f := Fetcher{
+		Logger: &logger,
+	}
+	for i, test := range tests {
+		u, err := url.Parse("tftp://" + conn.LocalAddr().String() + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requested = nil
+		if _, err := f.FetchToBuffer(*u, FetchOptions{}); err != nil {
+			t.Errorf("#%d: fetching: %v", i, err)
+			continue
+		}
+		if len(requested) != 1 || requested[0] != test.filename {
+			t.Errorf("#%d: expected request for %q, got %v", i, test.filename, requested)
+		}
+	}
+}
@@ -30,6 +30,7 @@ type Operation struct {
 	dev       string
 	wipe      bool
 	parts     []Partition
+	updates   []Partition
 	deletions []int
 	infos     []int
 }
@@ -56,6 +57,12 @@ func (op *Operation) CreatePartition(p Partition) {
 	op.parts = append(op.parts, p)
 }
 
+// UpdatePartition adds the supplied partition to the list of existing partitions whose type GUID and label
+// are to be changed in place as part of an operation. The partition's start, size, and GUID are ignored.
+func (op *Operation) UpdatePartition(p Partition) {
+	op.updates = append(op.updates, p)
+}
+
 func (op *Operation) DeletePartition(num int) {
 	op.deletions = append(op.deletions, num)
 }
@@ -118,7 +125,7 @@ func (op *Operation) Commit() error {
 	}
 	op.logger.Info("running sgdisk with options: %v", opts)
 	cmd := exec.Command(distro.SgdiskCmd(), opts...)
-	if _, err := op.logger.LogCmd(cmd, "deleting %d partitions and creating %d partitions on %q", len(op.deletions), len(op.parts), op.dev); err != nil {
+	if _, err := op.logger.LogCmd(cmd, "deleting %d partitions, updating %d partitions, and creating %d partitions on %q", len(op.deletions), len(op.updates), len(op.parts), op.dev); err != nil {
 		return fmt.Errorf("create partitions failed: %v", err)
 	}
 
@@ -150,6 +157,15 @@ func (op Operation) buildOptions() []string {
 		}
 	}
 
+	for _, p := range op.updates {
+		if p.Label != nil {
+			opts = append(opts, fmt.Sprintf("--change-name=%d:%s", p.Number, *p.Label))
+		}
+		if util.NotEmpty(p.TypeGUID) {
+			opts = append(opts, fmt.Sprintf("--typecode=%d:%s", p.Number, *p.TypeGUID))
+		}
+	}
+
 	for _, partition := range op.infos {
 		opts = append(opts, fmt.Sprintf("--info=%d", partition))
 	}
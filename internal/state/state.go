@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
 )
@@ -41,6 +42,12 @@ type State struct {
 	// the filesystem during files stage.  This is for special
 	// circumstances only.
 	ProviderOutputFiles []types.File `json:"providerOutputFiles"`
+	// When the first stage started.  Used to enforce the provisioning
+	// budget across stages.
+	ProvisioningStart time.Time `json:"provisioningStart"`
+	// Optional operations skipped because the provisioning budget ran
+	// out.  Reported in the result file in files stage.
+	SkippedOperations []string `json:"skippedOperations"`
 }
 
 type FetchedConfig struct {
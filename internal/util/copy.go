@@ -0,0 +1,76 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// CopyBufferSize is the size of the buffer used when the kernel can't
+	// copy between two files directly. It's a multiple of every common
+	// logical block size, so writes to block devices stay aligned, and
+	// large enough to keep NVMe queues busy.
+	CopyBufferSize = 4 * 1024 * 1024
+
+	// maximum length of a single copy_file_range(2) call
+	maxCopyFileRange = 1 << 30
+)
+
+// CopyFile copies from src to dst, starting at their current offsets, until
+// EOF on src. It lets the kernel copy the data with copy_file_range(2) if the
+// files support it, and otherwise falls back to reading and writing with a
+// buffer of CopyBufferSize. It returns the number of bytes copied.
+func CopyFile(dst, src *os.File) (int64, error) {
+	// best effort; only affects readahead
+	_ = unix.Fadvise(int(src.Fd()), 0, 0, unix.FADV_SEQUENTIAL)
+
+	var written int64
+	for {
+		n, err := unix.CopyFileRange(int(src.Fd()), nil, int(dst.Fd()), nil, maxCopyFileRange, 0)
+		if err != nil {
+			if written == 0 && copyFileRangeUnsupported(err) {
+				break
+			}
+			return written, err
+		}
+		if n == 0 {
+			return written, nil
+		}
+		written += int64(n)
+	}
+
+	// hide ReadFrom and WriteTo so io.CopyBuffer uses our buffer rather
+	// than *os.File's own small one
+	n, err := io.CopyBuffer(struct{ io.Writer }{dst}, struct{ io.Reader }{src}, make([]byte, CopyBufferSize))
+	return written + n, err
+}
+
+// copyFileRangeUnsupported returns whether err indicates copy_file_range(2)
+// can't be used for this pair of files at all, e.g. because one of them is a
+// block device, they're on different filesystems on an old kernel, or dst was
+// opened with O_APPEND.
+func copyFileRangeUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOSYS) ||
+		errors.Is(err, unix.EXDEV) ||
+		errors.Is(err, unix.EINVAL) ||
+		errors.Is(err, unix.EBADF) ||
+		errors.Is(err, unix.EOPNOTSUPP) ||
+		errors.Is(err, unix.EPERM)
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeRandomFile(t testing.TB, path string, size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(int64(size))).Read(data)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestCopyFile(t *testing.T) {
+	tests := []struct {
+		size int
+		flag int
+	}{
+		{0, os.O_WRONLY},
+		{1, os.O_WRONLY},
+		{CopyBufferSize + 1, os.O_WRONLY},
+		// copy_file_range(2) refuses O_APPEND destinations
+		{1, os.O_WRONLY | os.O_APPEND},
+		{CopyBufferSize + 1, os.O_WRONLY | os.O_APPEND},
+	}
+
+	for i, test := range tests {
+		dir := t.TempDir()
+		data := writeRandomFile(t, filepath.Join(dir, "src"), test.size)
+		prefix := []byte("prefix")
+		if err := os.WriteFile(filepath.Join(dir, "dst"), prefix, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		src, err := os.Open(filepath.Join(dir, "src"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst, err := os.OpenFile(filepath.Join(dir, "dst"), test.flag, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := dst.Seek(int64(len(prefix)), io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		n, err := CopyFile(dst, src)
+		src.Close()
+		dst.Close()
+		if err != nil {
+			t.Errorf("#%d: copy failed: %v", i, err)
+			continue
+		}
+		if n != int64(test.size) {
+			t.Errorf("#%d: bad length: want %d, got %d", i, test.size, n)
+		}
+		result, err := os.ReadFile(filepath.Join(dir, "dst"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(append(prefix, data...), result) {
+			t.Errorf("#%d: bad contents", i)
+		}
+	}
+}
+
+func benchmarkCopy(b *testing.B, flag int, copy func(dst, src *os.File) (int64, error)) {
+	const size = 256 * 1024 * 1024
+	dir := b.TempDir()
+	writeRandomFile(b, filepath.Join(dir, "src"), size)
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		src, err := os.Open(filepath.Join(dir, "src"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		dst, err := os.OpenFile(filepath.Join(dir, "dst"), flag|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := copy(dst, src); err != nil {
+			b.Fatal(err)
+		}
+		if err := dst.Sync(); err != nil {
+			b.Fatal(err)
+		}
+		src.Close()
+		dst.Close()
+	}
+}
+
+func ioCopy(dst, src *os.File) (int64, error) {
+	return io.Copy(dst, src)
+}
+
+func BenchmarkCopyFile(b *testing.B) {
+	benchmarkCopy(b, os.O_WRONLY, CopyFile)
+}
+
+func BenchmarkCopyFileAppend(b *testing.B) {
+	benchmarkCopy(b, os.O_WRONLY|os.O_APPEND, CopyFile)
+}
+
+func BenchmarkIoCopy(b *testing.B) {
+	benchmarkCopy(b, os.O_WRONLY, ioCopy)
+}
+
+func BenchmarkIoCopyAppend(b *testing.B) {
+	benchmarkCopy(b, os.O_WRONLY|os.O_APPEND, ioCopy)
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// DirectWriter buffers writes to a file opened with O_DIRECT so that they
+// are issued in large chunks from an aligned buffer. Flush must be called
+// after the last write.
+type DirectWriter struct {
+	file  *os.File
+	align int
+	buf   []byte
+	n     int
+}
+
+// NewDirectWriter returns a DirectWriter for file, whose writes must be
+// aligned to align bytes, which must be a power of two no larger than
+// CopyBufferSize. file may have been opened without O_DIRECT, in which case
+// the writer only adds buffering.
+func NewDirectWriter(file *os.File, align int) *DirectWriter {
+	// O_DIRECT also requires the buffer's address to be aligned
+	raw := make([]byte, CopyBufferSize+align)
+	off := 0
+	if rem := int(uintptr(unsafe.Pointer(&raw[0])) & uintptr(align-1)); rem != 0 {
+		off = align - rem
+	}
+	return &DirectWriter{
+		file:  file,
+		align: align,
+		buf:   raw[off : off+CopyBufferSize],
+	}
+}
+
+func (w *DirectWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		c := copy(w.buf[w.n:], p)
+		w.n += c
+		written += c
+		p = p[c:]
+		if w.n == len(w.buf) {
+			if err := w.write(w.buf); err != nil {
+				return written, err
+			}
+			w.n = 0
+		}
+	}
+	return written, nil
+}
+
+// Flush writes out any buffered data and syncs the file. The final partial
+// block, if any, is written with O_DIRECT cleared.
+func (w *DirectWriter) Flush() error {
+	aligned := w.n &^ (w.align - 1)
+	if err := w.write(w.buf[:aligned]); err != nil {
+		return err
+	}
+	if tail := w.buf[aligned:w.n]; len(tail) > 0 {
+		flags, err := unix.FcntlInt(w.file.Fd(), unix.F_GETFL, 0)
+		if err != nil {
+			return fmt.Errorf("getting flags of %q: %v", w.file.Name(), err)
+		}
+		if _, err := unix.FcntlInt(w.file.Fd(), unix.F_SETFL, flags&^unix.O_DIRECT); err != nil {
+			return fmt.Errorf("clearing O_DIRECT on %q: %v", w.file.Name(), err)
+		}
+		if err := w.write(tail); err != nil {
+			return err
+		}
+	}
+	w.n = 0
+	return w.file.Sync()
+}
+
+func (w *DirectWriter) write(p []byte) error {
+	for len(p) > 0 {
+		n, err := w.file.Write(p)
+		if err != nil {
+			return err
+		}
+		p = p[n:]
+	}
+	return nil
+}
+
+// BlockDeviceSectorSize returns the logical sector size of the block device
+// open as file.
+func BlockDeviceSectorSize(file *os.File) (int, error) {
+	size, err := unix.IoctlGetInt(int(file.Fd()), unix.BLKSSZGET)
+	if err != nil {
+		return 0, fmt.Errorf("getting sector size of %q: %v", file.Name(), err)
+	}
+	return size, nil
+}
+
+// BlockDeviceSize returns the size in bytes of the block device open as
+// file.
+func BlockDeviceSize(file *os.File) (int64, error) {
+	var size uint64
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, file.Fd(), unix.BLKGETSIZE64, uintptr(unsafe.Pointer(&size))); errno != 0 {
+		return 0, fmt.Errorf("getting size of %q: %v", file.Name(), errno)
+	}
+	return int64(size), nil
+}
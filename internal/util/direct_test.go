@@ -0,0 +1,73 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+)
+
+func TestDirectWriter(t *testing.T) {
+	tests := []struct {
+		size   int
+		chunks int
+	}{
+		{0, 1},
+		{100, 1},
+		{512, 1},
+		{CopyBufferSize, 3},
+		{2*CopyBufferSize + 1000, 7},
+	}
+
+	for i, test := range tests {
+		path := filepath.Join(t.TempDir(), "out")
+		data := make([]byte, test.size)
+		for j := range data {
+			data[j] = byte(j % 251)
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := NewDirectWriter(file, 512)
+		if uintptr(unsafe.Pointer(&w.buf[0]))%512 != 0 {
+			t.Errorf("#%d: buffer isn't aligned", i)
+		}
+		chunk := test.size/test.chunks + 1
+		for off := 0; off < test.size; off += chunk {
+			end := off + chunk
+			if end > test.size {
+				end = test.size
+			}
+			if _, err := w.Write(data[off:end]); err != nil {
+				t.Fatalf("#%d: write failed: %v", i, err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("#%d: flush failed: %v", i, err)
+		}
+		file.Close()
+		result, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, result) {
+			t.Errorf("#%d: bad contents", i)
+		}
+	}
+}
@@ -15,14 +15,13 @@
 package util
 
 import (
-	"crypto/sha256"
-	"crypto/sha512"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"hash"
 	"strings"
 
+	cutil "github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/ignition/v2/config/v3_5_experimental/types"
 )
 
@@ -65,17 +64,13 @@ func AssertValid(verify types.Verification, data []byte) error {
 			return err
 		}
 
-		var sum []byte
-		switch hashFunc {
-		case "sha512":
-			rawSum := sha512.Sum512(data)
-			sum = rawSum[:]
-		case "sha256":
-			rawSum := sha256.Sum256(data)
-			sum = rawSum[:]
-		default:
+		f, ok := cutil.GetHashFunction(hashFunc)
+		if !ok {
 			return ErrHashUnrecognized
 		}
+		hasher := f.New()
+		hasher.Write(data)
+		sum := hasher.Sum(nil)
 
 		encodedSum := make([]byte, hex.EncodedLen(len(sum)))
 		hex.Encode(encodedSum, sum)
@@ -90,6 +85,9 @@ func AssertValid(verify types.Verification, data []byte) error {
 	return nil
 }
 
+// GetHasher returns a new hash.Hash for the hash function of verify, which
+// can be any registered with config/util.RegisterHashFunction, or nil if
+// verify has no hash.
 func GetHasher(verify types.Verification) (hash.Hash, error) {
 	if verify.Hash == nil {
 		return nil, nil
@@ -100,12 +98,9 @@ func GetHasher(verify types.Verification) (hash.Hash, error) {
 		return nil, err
 	}
 
-	switch function {
-	case "sha512":
-		return sha512.New(), nil
-	case "sha256":
-		return sha256.New(), nil
-	default:
+	f, ok := cutil.GetHashFunction(function)
+	if !ok {
 		return nil, ErrHashUnrecognized
 	}
+	return f.New(), nil
 }
@@ -26,6 +26,7 @@ func init() {
 	register.Register(register.NegativeTest, ForceHardLinkCreation())
 	register.Register(register.NegativeTest, ForceFileCreationOverNonemptyDir())
 	register.Register(register.NegativeTest, ForceLinkCreationOverNonemptyDir())
+	register.Register(register.NegativeTest, WriteDeviceOverDirectory())
 }
 
 func ForceFileCreation() types.Test {
@@ -248,3 +249,30 @@ func ForceLinkCreationOverNonemptyDir() types.Test {
 		ConfigMinVersion: configMinVersion,
 	}
 }
+
+func WriteDeviceOverDirectory() types.Test {
+	name := "files.create.device.overdir"
+	in := types.GetBaseDisk()
+	out := types.GetBaseDisk()
+	config := `{
+	  "ignition": { "version": "$version" },
+	  "storage": {
+	    "files": [{
+	      "path": "/dev/shm",
+	      "contents": {
+	        "source": "http://127.0.0.1:8080/contents"
+	      },
+	      "device": true
+	    }]
+	  }
+	}`
+	configMinVersion := "3.5.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
@@ -35,6 +35,9 @@ func init() {
 	register.Register(register.NegativeTest, VersionOnlyConfig25())
 	register.Register(register.NegativeTest, VersionOnlyConfig35())
 	register.Register(register.NegativeTest, MergingCanFail())
+	register.Register(register.NegativeTest, MergedConfigOutsideRestrictions())
+	register.Register(register.NegativeTest, MergedConfigKernelArgumentsRestricted())
+	register.Register(register.NegativeTest, MergedConfigUnitOutsideRestrictions())
 }
 
 func ReplaceConfigWithInvalidHash() types.Test {
@@ -351,3 +354,120 @@ func MergingCanFail() types.Test {
 		ConfigMinVersion: configMinVersion,
 	}
 }
+
+func MergedConfigOutsideRestrictions() types.Test {
+	name := "config.merge.restricted"
+	configMinVersion := "3.5.0-experimental"
+	in := types.GetBaseDisk()
+	out := in
+	mergedConfig := `{
+	  "ignition": {
+	    "version": "3.5.0-experimental"
+	  },
+	  "storage": {
+	    "files": [{
+	      "path": "/etc/tenant",
+	      "contents": { "source": "data:,example%20file%0A" }
+	    }]
+	  }
+	}`
+
+	config := fmt.Sprintf(`{
+	  "ignition": {
+	    "version": "$version",
+	    "config": {
+	      "merge": [{
+	        "source": "%s"
+	      }],
+	      "restrictions": {
+	        "allowedPaths": ["/var/lib/tenant"]
+	      }
+	    }
+	  }
+	}`, dataurl.EncodeBytes([]byte(mergedConfig)))
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
+
+func MergedConfigKernelArgumentsRestricted() types.Test {
+	name := "config.merge.restricted.kargs"
+	configMinVersion := "3.5.0-experimental"
+	in := types.GetBaseDisk()
+	out := in
+	mergedConfig := `{
+	  "ignition": {
+	    "version": "3.5.0-experimental"
+	  },
+	  "kernelArguments": {
+	    "shouldExist": ["init=/bin/sh"]
+	  }
+	}`
+
+	config := fmt.Sprintf(`{
+	  "ignition": {
+	    "version": "$version",
+	    "config": {
+	      "merge": [{
+	        "source": "%s"
+	      }],
+	      "restrictions": {
+	        "denyBoot": true
+	      }
+	    }
+	  }
+	}`, dataurl.EncodeBytes([]byte(mergedConfig)))
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
+
+func MergedConfigUnitOutsideRestrictions() types.Test {
+	name := "config.merge.restricted.units"
+	configMinVersion := "3.5.0-experimental"
+	in := types.GetBaseDisk()
+	out := in
+	mergedConfig := `{
+	  "ignition": {
+	    "version": "3.5.0-experimental"
+	  },
+	  "systemd": {
+	    "units": [{
+	      "name": "tenant.service",
+	      "contents": "[Service]\nExecStart=/bin/true"
+	    }]
+	  }
+	}`
+
+	config := fmt.Sprintf(`{
+	  "ignition": {
+	    "version": "$version",
+	    "config": {
+	      "merge": [{
+	        "source": "%s"
+	      }],
+	      "restrictions": {
+	        "allowedPaths": ["/var/lib/tenant"]
+	      }
+	    }
+	  }
+	}`, dataurl.EncodeBytes([]byte(mergedConfig)))
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package files
+
+import (
+	"github.com/coreos/ignition/v2/tests/register"
+	"github.com/coreos/ignition/v2/tests/types"
+)
+
+func init() {
+	register.Register(register.PositiveTest, WriteBlockDevice())
+}
+
+func WriteBlockDevice() types.Test {
+	name := "files.create.device.block"
+	in := types.GetBaseDisk()
+	out := types.GetBaseDisk()
+	mntDevices := []types.MntDevice{
+		{
+			Label:        "OEM",
+			Substitution: "$DEVICE",
+		},
+	}
+	// a gzipped swap header, which replaces the ext4 superblock
+	config := `{
+	  "ignition": { "version": "$version" },
+	  "storage": {
+	    "files": [{
+	      "path": "$DEVICE",
+	      "contents": {
+	        "compression": "gzip",
+	        "source": "data:;base64,H4sIAAAAAAACA2NgGAWjYBSMVMAIxSCQZS3Pp+Pr1z+LW0bXzj8gMSW1rLwosyR1NJRGwSgYBaNgFIyCUTAKRsEoGMogONwxIDjA0dnVCADE/oVtABAAAA=="
+	      },
+	      "device": true
+	    }]
+	  }
+	}`
+	configMinVersion := "3.5.0-experimental"
+	out[0].Partitions.GetPartition("OEM").FilesystemType = "swap"
+	out[0].Partitions.GetPartition("OEM").FilesystemLabel = "devwrite"
+	out[0].Partitions.GetPartition("OEM").FilesystemUUID = "6a3b1f0e-2c4d-4e8f-9a0b-1c2d3e4f5061"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		MntDevices:       mntDevices,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
@@ -26,6 +26,8 @@ func init() {
 	register.Register(register.PositiveTest, DirCreationOverNonemptyDir())
 	register.Register(register.PositiveTest, CheckOrdering())
 	register.Register(register.PositiveTest, ApplyDefaultDirectoryPermissions())
+	register.Register(register.PositiveTest, CreateDirectoryWithModeString())
+	register.Register(register.PositiveTest, DirCreationOnConflict())
 }
 
 func CreateDirectoryOnRoot() types.Test {
@@ -279,3 +281,108 @@ func ApplyDefaultDirectoryPermissions() types.Test {
 		ConfigMinVersion: configMinVersion,
 	}
 }
+
+func CreateDirectoryWithModeString() types.Test {
+	name := "directories.create.mode.string"
+	in := types.GetBaseDisk()
+	out := types.GetBaseDisk()
+	config := `{
+	  "ignition": { "version": "$version" },
+	  "storage": {
+	    "directories": [{
+	      "path": "/foo/bar",
+	      "mode": "0750"
+	    },
+	    {
+	      "path": "/foo/baz",
+	      "mode": "u=rwx,g=rx"
+	    }]
+	  }
+	}`
+	out[0].Partitions.AddDirectories("ROOT", []types.Directory{
+		{
+			Node: types.Node{
+				Directory: "foo",
+				Name:      "bar",
+			},
+			Mode: 0750,
+		},
+		{
+			Node: types.Node{
+				Directory: "foo",
+				Name:      "baz",
+			},
+			Mode: 0750,
+		},
+	})
+	configMinVersion := "3.5.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
+
+func DirCreationOnConflict() types.Test {
+	name := "directories.create.onconflict"
+	in := types.GetBaseDisk()
+	out := types.GetBaseDisk()
+	config := `{
+	  "ignition": { "version": "$version" },
+	  "storage": {
+	    "directories": [{
+	      "path": "/foo/bar",
+	      "onConflict": "replace"
+	    },
+	    {
+	      "path": "/foo/baz",
+	      "onConflict": "keep"
+	    }]
+	  }
+	}`
+	in[0].Partitions.AddFiles("ROOT", []types.File{
+		{
+			Node: types.Node{
+				Directory: "foo",
+				Name:      "bar",
+			},
+			Contents: "hello, world",
+		},
+		{
+			Node: types.Node{
+				Directory: "foo",
+				Name:      "baz",
+			},
+			Contents: "hello, world",
+		},
+	})
+	out[0].Partitions.AddDirectories("ROOT", []types.Directory{
+		{
+			Node: types.Node{
+				Directory: "foo",
+				Name:      "bar",
+			},
+		},
+	})
+	out[0].Partitions.AddFiles("ROOT", []types.File{
+		{
+			Node: types.Node{
+				Directory: "foo",
+				Name:      "baz",
+			},
+			Contents: "hello, world",
+		},
+	})
+	configMinVersion := "3.5.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
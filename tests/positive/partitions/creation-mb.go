@@ -27,6 +27,7 @@ func init() {
 	register.Register(register.PositiveTest, AppendPartitionsMiB())
 	register.Register(register.PositiveTest, ResizeRootMiB())
 	register.Register(register.PositiveTest, ResizeExistingPartitionsMiB())
+	register.Register(register.PositiveTest, RetypeExistingPartitionMiB())
 }
 
 func CreatePartitionMiB() types.Test {
@@ -365,3 +366,40 @@ func ResizeExistingPartitionsMiB() types.Test {
 		ConfigMinVersion: "3.2.0",
 	}
 }
+
+// RetypeExistingPartitionMiB verifies that the type GUID and label of the
+// existing partition can be changed in place if `retype` field is set to
+// true and partition matches in all respects except type GUID and label.
+func RetypeExistingPartitionMiB() types.Test {
+	name := "partition.retypeExistingPartition"
+	in := types.GetBaseDisk()
+	out := types.GetBaseDisk()
+	out[0].Partitions[1].Label = "DATA"
+	out[0].Partitions[1].TypeCode = "coreos-rootfs"
+	config := `{
+			"ignition": {
+				"version": "$version"
+			},
+			"storage": {
+				"disks": [{
+					"device": "$disk0",
+					"wipeTable": false,
+					"partitions": [{
+						"label": "DATA",
+						"number": 6,
+						"typeGuid": "5DFBF5F4-2848-4BAC-AA5E-0D9A20B745A6",
+						"retype": true
+					}
+					]
+				}]
+			}
+		}`
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: "3.5.0-experimental",
+	}
+}